@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/batkinson/checkers-go/checkers/ai"
+	"github.com/batkinson/checkers-go/checkers/bot"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// engine builds the named engine. Seed 0 seeds it from the clock.
+func engine(name string, depth, playouts int, seed int64) (ai.Engine, error) {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	switch name {
+	case "random":
+		return ai.NewRandom(seed), nil
+	case "alphabeta":
+		return ai.NewAlphaBeta(depth, seed), nil
+	case "mcts":
+		return ai.NewMCTS(playouts, seed), nil
+	}
+	return nil, fmt.Errorf("unknown engine %q, expected random, alphabeta or mcts", name)
+}
+
+func main() {
+	addr := flag.String("server", "localhost:5000", "address of the checkers server")
+	games := flag.Int("games", 1, "number of games to play at once")
+	name := flag.String("engine", "alphabeta", "engine to play with: random, alphabeta or mcts")
+	depth := flag.Int("depth", ai.DEFAULT_DEPTH, "search depth for alphabeta")
+	playouts := flag.Int("playouts", ai.DEFAULT_PLAYOUTS, "playouts per move for mcts")
+	seed := flag.Int64("seed", 0, "random seed, or 0 to seed from the clock")
+	newArgs := flag.String("new", "", "arguments for NEW when creating a game, such as BALLOT")
+	retry := flag.Duration("retry", bot.RETRY_DELAY, "delay before reconnecting or retrying")
+	flag.Parse()
+	if *games < 1 {
+		log.Fatal("-games must be at least 1")
+	}
+	e, err := engine(*name, *depth, *playouts, *seed)
+	if err != nil {
+		log.Fatal(err)
+	}
+	b := bot.New(*addr, e)
+	b.Games = *games
+	b.NewArgs = strings.Fields(*newArgs)
+	b.Retry = *retry
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		cancel()
+	}()
+	b.Run(ctx)
+}
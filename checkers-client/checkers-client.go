@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/batkinson/checkers-go/checkers/client"
+	"io"
+	"log"
+	"os"
+	"time"
+)
+
+// LOBBY_REFRESH is how often the lobby's lists of games are updated.
+const LOBBY_REFRESH = 2 * time.Second
+
+func main() {
+	addr := flag.String("server", "localhost:5000", "address of the checkers server")
+	ascii := flag.Bool("ascii", false, "draw pieces as letters instead of Unicode")
+	flag.Parse()
+	c, err := client.Dial(*addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	restore, err := rawMode()
+	if err != nil {
+		log.Fatal(err)
+	}
+	keys := make(chan Key)
+	go readKeys(os.Stdin, keys)
+	fmt.Print(ENTER_SCREEN)
+	err = run(NewUI(c, *addr, !*ascii), keys, os.Stdout)
+	fmt.Print(LEAVE_SCREEN)
+	restore()
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run redraws the screen after every key, event and lobby refresh until the
+// user quits or the connection ends.
+func run(ui *UI, keys <-chan Key, out io.Writer) error {
+	ticker := time.NewTicker(LOBBY_REFRESH)
+	defer ticker.Stop()
+	ui.refresh()
+	for !ui.done {
+		draw(out, ui.Lines())
+		select {
+		case key, ok := <-keys:
+			if ok {
+				ui.HandleKey(key)
+			} else {
+				ui.quit()
+			}
+		case event, ok := <-ui.client.Events:
+			if !ok {
+				return ui.client.Err()
+			}
+			ui.HandleEvent(event)
+		case <-ticker.C:
+			if !ui.inGame() {
+				ui.refresh()
+			}
+		}
+	}
+	return nil
+}
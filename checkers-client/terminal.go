@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const (
+	ENTER_SCREEN = "\x1b[?1049h\x1b[?25l"
+	LEAVE_SCREEN = "\x1b[?25h\x1b[?1049l"
+	CLEAR_SCREEN = "\x1b[H\x1b[2J"
+)
+
+type KeyType int
+
+const (
+	KEY_RUNE KeyType = iota
+	KEY_UP
+	KEY_DOWN
+	KEY_LEFT
+	KEY_RIGHT
+	KEY_ENTER
+	KEY_BACKSPACE
+	KEY_ESCAPE
+	KEY_INTERRUPT
+)
+
+// Key is one key press. Rune is set for KEY_RUNE.
+type Key struct {
+	Type KeyType
+	Rune rune
+}
+
+var escapeKeys = map[string]KeyType{
+	"\x1b[A": KEY_UP,
+	"\x1b[B": KEY_DOWN,
+	"\x1b[C": KEY_RIGHT,
+	"\x1b[D": KEY_LEFT,
+	"\x1bOA": KEY_UP,
+	"\x1bOB": KEY_DOWN,
+	"\x1bOC": KEY_RIGHT,
+	"\x1bOD": KEY_LEFT,
+}
+
+// decodeKeys splits what one read from a raw terminal returned into keys.
+// Escape sequences it does not know are dropped.
+func decodeKeys(input string) []Key {
+	keys := []Key{}
+	for len(input) > 0 {
+		if input[0] == '\x1b' {
+			if len(input) >= 3 && (input[1] == '[' || input[1] == 'O') {
+				if key, ok := escapeKeys[input[:3]]; ok {
+					keys = append(keys, Key{Type: key})
+				}
+				input = input[3:]
+				continue
+			}
+			keys = append(keys, Key{Type: KEY_ESCAPE})
+			input = input[1:]
+			continue
+		}
+		r := []rune(input)[0]
+		input = input[len(string(r)):]
+		switch r {
+		case '\r', '\n':
+			keys = append(keys, Key{Type: KEY_ENTER})
+		case '\x7f', '\b':
+			keys = append(keys, Key{Type: KEY_BACKSPACE})
+		case '\x03', '\x04':
+			keys = append(keys, Key{Type: KEY_INTERRUPT})
+		default:
+			keys = append(keys, Key{Type: KEY_RUNE, Rune: r})
+		}
+	}
+	return keys
+}
+
+// readKeys sends the keys read from r until it fails.
+func readKeys(r io.Reader, keys chan<- Key) {
+	defer close(keys)
+	buf := make([]byte, 64)
+	for {
+		n, err := r.Read(buf)
+		for _, key := range decodeKeys(string(buf[:n])) {
+			keys <- key
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// rawMode stops the terminal echoing and buffering input until the returned
+// function restores it.
+func rawMode() (restore func(), err error) {
+	state, err := stty("-g")
+	if err != nil {
+		return nil, fmt.Errorf("standard input is not a terminal: %v", err)
+	}
+	if _, err := stty("raw", "-echo"); err != nil {
+		return nil, err
+	}
+	return func() { stty(state) }, nil
+}
+
+// draw replaces the screen with lines.
+func draw(w io.Writer, lines []string) {
+	fmt.Fprint(w, CLEAR_SCREEN+strings.Join(lines, "\r\n"))
+}
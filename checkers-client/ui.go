@@ -0,0 +1,318 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/batkinson/checkers-go/checkers"
+	"github.com/batkinson/checkers-go/checkers/client"
+	"github.com/batkinson/checkers-go/checkers/render"
+	"strings"
+	"unicode"
+)
+
+const (
+	LOBBY_HELP = "n new game   enter join or watch   r refresh   q quit"
+	GAME_HELP  = "arrows move   space pick up and put down   type 11-15 or 22x15x8 and enter   l leave   q quit"
+)
+
+var errInvalidNotation = errors.New("expected a move such as 11-15 or 22x15x8")
+
+// entry is a game listed in the lobby.
+type entry struct {
+	gameId   string
+	spectate bool
+}
+
+// UI is the state of the screen: the lobby when the client is in no game,
+// otherwise the board. Keys and events update it and Lines draws it.
+type UI struct {
+	client   *client.Client
+	server   string
+	unicode  bool
+	games    []entry
+	selected int
+	cursor   checkers.Pos
+	picked   checkers.Pos
+	input    string
+	message  string
+	done     bool
+}
+
+func NewUI(c *client.Client, server string, unicode bool) *UI {
+	return &UI{client: c, server: server, unicode: unicode, cursor: checkers.Pos{X: 3, Y: 4}, picked: checkers.NO_POS}
+}
+
+func (ui *UI) inGame() bool {
+	return ui.client.GameId() != ""
+}
+
+func (ui *UI) report(err error) {
+	if err != nil {
+		ui.message = err.Error()
+	}
+}
+
+// refresh lists the games that can be joined, then those that can be watched.
+func (ui *UI) refresh() {
+	waiting, err := ui.client.List()
+	if err != nil {
+		ui.report(err)
+		return
+	}
+	watchable, err := ui.client.ListSpectate()
+	if err != nil {
+		ui.report(err)
+		return
+	}
+	ui.games = []entry{}
+	for _, id := range waiting {
+		ui.games = append(ui.games, entry{id, false})
+	}
+	for _, id := range watchable {
+		ui.games = append(ui.games, entry{id, true})
+	}
+	if ui.selected >= len(ui.games) {
+		ui.selected = len(ui.games) - 1
+	}
+	if ui.selected < 0 {
+		ui.selected = 0
+	}
+}
+
+func (ui *UI) quit() {
+	ui.client.Quit()
+	ui.done = true
+}
+
+func (ui *UI) Lines() []string {
+	if ui.inGame() {
+		return ui.gameLines()
+	}
+	return ui.lobbyLines()
+}
+
+func (ui *UI) lobbyLines() []string {
+	lines := []string{fmt.Sprintf("Checkers on %v", ui.server), ""}
+	for _, section := range []struct {
+		title    string
+		spectate bool
+	}{{"Games waiting for a player:", false}, {"Games to watch:", true}} {
+		lines = append(lines, section.title)
+		listed := false
+		for i, game := range ui.games {
+			if game.spectate != section.spectate {
+				continue
+			}
+			marker := "  "
+			if i == ui.selected {
+				marker = "> "
+			}
+			lines = append(lines, marker+game.gameId)
+			listed = true
+		}
+		if !listed {
+			lines = append(lines, "  (none)")
+		}
+		lines = append(lines, "")
+	}
+	return append(lines, LOBBY_HELP, ui.message)
+}
+
+// flipped reports whether the board is drawn from red's side, which black's
+// player sees so their own pieces are at the bottom.
+func (ui *UI) flipped() bool {
+	return ui.client.Player() == checkers.BLACK_PLAYER
+}
+
+func title(s string) string {
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func (ui *UI) status() string {
+	player, turn, winner := ui.client.Player(), ui.client.Turn(), ui.client.Winner()
+	switch {
+	case winner != checkers.NO_PLAYER:
+		return fmt.Sprintf("%v wins", title(winner.Color))
+	case turn == checkers.NO_PLAYER:
+		return "Waiting for an opponent"
+	case turn == player:
+		return "Your move"
+	}
+	return fmt.Sprintf("%v to move", title(turn.Color))
+}
+
+func (ui *UI) gameLines() []string {
+	role := "Watching"
+	if player := ui.client.Player(); player != checkers.NO_PLAYER {
+		role = "You are " + player.Color
+	}
+	lines := []string{fmt.Sprintf("Game %v   %v   %v", ui.client.GameId(), role, ui.status()), ""}
+	if game := ui.client.Game(); game != nil {
+		opts := render.DefaultTextOptions()
+		opts.Unicode = ui.unicode
+		opts.Color = true
+		opts.Flip = ui.flipped()
+		opts.LastMove = ui.client.LastMove()
+		opts.Cursor = ui.cursor
+		opts.Selected = ui.picked
+		lines = append(lines, render.Lines(game, opts)...)
+	}
+	return append(lines, "", "Move: "+ui.input, "", GAME_HELP, ui.message)
+}
+
+func (ui *UI) HandleKey(key Key) {
+	if key.Type == KEY_INTERRUPT || (key.Type == KEY_RUNE && key.Rune == 'q' && ui.input == "") {
+		ui.quit()
+		return
+	}
+	ui.message = ""
+	if ui.inGame() {
+		ui.gameKey(key)
+	} else {
+		ui.lobbyKey(key)
+	}
+}
+
+func (ui *UI) lobbyKey(key Key) {
+	switch {
+	case key.Type == KEY_UP && ui.selected > 0:
+		ui.selected--
+	case key.Type == KEY_DOWN && ui.selected < len(ui.games)-1:
+		ui.selected++
+	case key.Type == KEY_ENTER && len(ui.games) > 0:
+		game := ui.games[ui.selected]
+		if game.spectate {
+			ui.report(ui.client.Spectate(game.gameId))
+		} else {
+			ui.report(ui.client.Join(game.gameId))
+		}
+		if !ui.inGame() {
+			ui.refresh()
+		}
+	case key.Type == KEY_RUNE && key.Rune == 'n':
+		_, err := ui.client.New()
+		ui.report(err)
+	case key.Type == KEY_RUNE && key.Rune == 'r':
+		ui.refresh()
+	}
+}
+
+func (ui *UI) gameKey(key Key) {
+	up, right := 1, 1
+	if ui.flipped() {
+		up, right = -1, -1
+	}
+	switch {
+	case key.Type == KEY_UP:
+		ui.moveCursor(0, -up)
+	case key.Type == KEY_DOWN:
+		ui.moveCursor(0, up)
+	case key.Type == KEY_LEFT:
+		ui.moveCursor(-right, 0)
+	case key.Type == KEY_RIGHT:
+		ui.moveCursor(right, 0)
+	case key.Type == KEY_ESCAPE:
+		ui.input, ui.picked = "", checkers.NO_POS
+	case key.Type == KEY_BACKSPACE && ui.input != "":
+		ui.input = ui.input[:len(ui.input)-1]
+	case key.Type == KEY_ENTER && ui.input != "":
+		moves, err := parseNotation(ui.input)
+		ui.input = ""
+		if err != nil {
+			ui.report(err)
+			return
+		}
+		ui.move(moves...)
+	case key.Type == KEY_ENTER, key.Type == KEY_RUNE && key.Rune == ' ':
+		ui.pick()
+	case key.Type == KEY_RUNE && (unicode.IsDigit(key.Rune) || strings.ContainsRune(checkers.MOVE_SEP+checkers.JUMP_SEP, key.Rune)):
+		ui.input += string(key.Rune)
+	case key.Type == KEY_RUNE && key.Rune == 'l':
+		ui.report(ui.client.Leave())
+		ui.input, ui.picked = "", checkers.NO_POS
+		ui.refresh()
+	}
+}
+
+func (ui *UI) moveCursor(dx, dy int) {
+	x, y := ui.cursor.X+dx, ui.cursor.Y+dy
+	if x >= 0 && x < checkers.BOARD_DIM && y >= 0 && y < checkers.BOARD_DIM {
+		ui.cursor = checkers.Pos{X: x, Y: y}
+	}
+}
+
+// pick picks up the piece under the cursor, puts it back, or moves the piece
+// picked up to the cursor.
+func (ui *UI) pick() {
+	player := ui.client.Player()
+	game := ui.client.Game()
+	if player == checkers.NO_PLAYER || game == nil {
+		ui.message = "Only players can move"
+		return
+	}
+	if ui.picked == checkers.NO_POS {
+		if piece, ok := game.Pieces[ui.cursor]; ok && piece.Player == player {
+			ui.picked = ui.cursor
+		}
+		return
+	}
+	if ui.picked == ui.cursor {
+		ui.picked = checkers.NO_POS
+		return
+	}
+	ui.move(checkers.Move{Src: ui.picked, Dst: ui.cursor})
+}
+
+// move sends each step of a move in turn. A piece that must keep jumping
+// stays picked up.
+func (ui *UI) move(moves ...checkers.Move) {
+	ui.picked = checkers.NO_POS
+	for _, move := range moves {
+		if err := ui.client.Move(move); err != nil {
+			ui.report(err)
+			return
+		}
+		ui.cursor = move.Dst
+	}
+	if game := ui.client.Game(); game != nil && game.Continuing() && game.TurnIs(ui.client.Player()) {
+		ui.picked = game.Jumper
+	}
+}
+
+// parseNotation parses a move in square-number notation, including a
+// multi-jump such as 22x15x8, into its steps. Whether the move is legal is
+// left to the server, whose board the client's copy may lag behind.
+func parseNotation(s string) ([]checkers.Move, error) {
+	if !strings.Contains(s, checkers.JUMP_SEP) {
+		move, err := checkers.ParseMove(s)
+		if err != nil {
+			return nil, errInvalidNotation
+		}
+		return []checkers.Move{move}, nil
+	}
+	squares := strings.Split(s, checkers.JUMP_SEP)
+	moves := []checkers.Move{}
+	for i := 1; i < len(squares); i++ {
+		move, err := checkers.ParseMove(squares[i-1] + checkers.JUMP_SEP + squares[i])
+		if err != nil {
+			return nil, errInvalidNotation
+		}
+		moves = append(moves, move)
+	}
+	return moves, nil
+}
+
+func (ui *UI) HandleEvent(event client.Event) {
+	switch event.Type {
+	case client.GAME_ID:
+		ui.cursor, ui.picked, ui.input = checkers.Pos{X: 3, Y: 4}, checkers.NO_POS, ""
+	case client.JOINED:
+		ui.message = event.Player.Color + " joined"
+	case client.LEFT:
+		ui.message = event.Player.Color + " left"
+	case client.DISCONNECTED:
+		ui.message = event.Player.Color + " disconnected, their seat is held"
+	case client.RESUMED:
+		ui.message = event.Player.Color + " is back"
+	}
+}
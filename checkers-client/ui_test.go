@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"github.com/batkinson/checkers-go/checkers"
+	"github.com/batkinson/checkers-go/checkers/client"
+	"github.com/batkinson/checkers-go/checkers/server"
+	"io/ioutil"
+	"log"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeKeys(t *testing.T) {
+	keys := decodeKeys("\x1b[A\x1bOD1x\r\x7f\x1b\x03é")
+	expected := []Key{
+		{Type: KEY_UP},
+		{Type: KEY_LEFT},
+		{Type: KEY_RUNE, Rune: '1'},
+		{Type: KEY_RUNE, Rune: 'x'},
+		{Type: KEY_ENTER},
+		{Type: KEY_BACKSPACE},
+		{Type: KEY_ESCAPE},
+		{Type: KEY_INTERRUPT},
+		{Type: KEY_RUNE, Rune: 'é'},
+	}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected %v, got %v", expected, keys)
+	}
+}
+
+func TestParseNotation(t *testing.T) {
+	moves, err := parseNotation("22x15x8")
+	expected := []checkers.Move{
+		{Src: checkers.SquarePos(22), Dst: checkers.SquarePos(15)},
+		{Src: checkers.SquarePos(15), Dst: checkers.SquarePos(8)},
+	}
+	if err != nil || !reflect.DeepEqual(moves, expected) {
+		t.Errorf("expected %v, got %v, %v", expected, moves, err)
+	}
+	for _, invalid := range []string{"", "11", "11-", "x", "11-15-19", "40-44", "22x15x"} {
+		if _, err := parseNotation(invalid); err != errInvalidNotation {
+			t.Errorf("expected %q to be invalid, got %v", invalid, err)
+		}
+	}
+}
+
+// connect returns UIs connected to a server started for the test.
+func connect(t *testing.T, count int) []*UI {
+	srv := server.New(server.DefaultConfig())
+	srv.Logger = log.New(ioutil.Discard, "", 0)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(listener)
+	uis := make([]*UI, count)
+	for i := range uis {
+		c, err := client.Dial(listener.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		uis[i] = NewUI(c, listener.Addr().String(), false)
+	}
+	t.Cleanup(func() {
+		for _, ui := range uis {
+			ui.client.Close()
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		srv.Shutdown(ctx)
+	})
+	return uis
+}
+
+func press(ui *UI, keys string) {
+	for _, key := range decodeKeys(keys) {
+		ui.HandleKey(key)
+	}
+}
+
+func TestPlayByKeys(t *testing.T) {
+	uis := connect(t, 2)
+	a, b := uis[0], uis[1]
+	press(a, "n")
+	b.refresh()
+	if !strings.Contains(strings.Join(b.Lines(), "\n"), "> "+a.client.GameId()) {
+		t.Fatalf("expected game %v to be listed, got\n%v", a.client.GameId(), strings.Join(b.Lines(), "\n"))
+	}
+	press(b, "\r")
+	if b.client.GameId() != a.client.GameId() {
+		t.Fatalf("expected to join %v, got %q: %v", a.client.GameId(), b.client.GameId(), b.message)
+	}
+	black, red := a, b
+	if b.client.Player() == checkers.BLACK_PLAYER {
+		black, red = b, a
+	}
+	// Black sees the board flipped, with its own pieces at the bottom. From
+	// the starting cursor on 3 4, pick up 11 on 5 2 and put it down on 15.
+	press(black, "\x1b[B\x1b[D\x1b[B\x1b[D"+" "+"\x1b[A\x1b[C"+" ")
+	if black.message != "" || black.client.LastMove().String() != "11-15" {
+		t.Fatalf("expected 11-15, got %v: %v", black.client.LastMove(), black.message)
+	}
+	press(red, "22-18\r")
+	if red.message != "" || red.client.LastMove().String() != "22-18" {
+		t.Fatalf("expected 22-18, got %v: %v", red.client.LastMove(), red.message)
+	}
+	press(black, "15x22\r")
+	if black.message != "" || black.client.Game().PieceAt(checkers.SquarePos(18)) {
+		t.Errorf("expected 15x22 to capture on 18, got %v", black.message)
+	}
+	press(black, "9-\r")
+	if black.message != errInvalidNotation.Error() || black.input != "" {
+		t.Errorf("expected the notation to be refused and cleared, got %q with %q typed", black.message, black.input)
+	}
+	press(black, "9-13\r")
+	if !strings.HasPrefix(black.message, "NOT_YOUR_TURN") {
+		t.Errorf("expected the move to be refused, got %q", black.message)
+	}
+	press(red, "l")
+	if red.client.GameId() != "" || !strings.Contains(strings.Join(red.Lines(), "\n"), "Games waiting for a player:") {
+		t.Errorf("expected to be back in the lobby, got\n%v", strings.Join(red.Lines(), "\n"))
+	}
+	press(black, "q")
+	if !black.done {
+		t.Errorf("expected q to quit")
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"github.com/batkinson/checkers-go/checkers"
+	"github.com/batkinson/checkers-go/checkers/client"
+	"github.com/batkinson/checkers-go/checkers/render"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+// readTranscript collects the moves from server STATUS lines, such as a
+// player's or spectator's session log. A STATUS BOARD and STATUS TURN seen
+// before the first move set the starting position.
+func readTranscript(r io.Reader) (start *checkers.Game, moves []checkers.Move, err error) {
+	start = checkers.New()
+	lines := bufio.NewScanner(r)
+	for lines.Scan() {
+		if !strings.HasPrefix(lines.Text(), "STATUS ") {
+			continue
+		}
+		event, err := client.ParseStatus(strings.TrimRight(lines.Text(), "\r"))
+		if err != nil && ((event.Type == client.BOARD && len(moves) == 0) || event.Type == client.MOVED) {
+			return nil, nil, err
+		}
+		switch {
+		case err != nil:
+		case event.Type == client.BOARD && len(moves) == 0:
+			start = event.Board
+		case event.Type == client.TURN && event.Player != checkers.NO_PLAYER && len(moves) == 0:
+			start.Turn = event.Player
+		case event.Type == client.MOVED:
+			moves = append(moves, event.Move)
+		}
+	}
+	return start, moves, lines.Err()
+}
+
+func main() {
+	opts := render.DefaultGIFOptions()
+	output := flag.String("o", "", "output file (default standard output)")
+	flag.DurationVar(&opts.FrameDelay, "delay", render.DEFAULT_FRAME_DELAY, "time each position is shown")
+	flag.DurationVar(&opts.FinalDelay, "final", render.DEFAULT_FINAL_DELAY, "time the final position is shown")
+	flag.IntVar(&opts.Board.SquareSize, "size", render.DEFAULT_SQUARE_SIZE, "square size in pixels")
+	flag.BoolVar(&opts.Board.Flip, "flip", false, "draw the board from red's side")
+	flag.BoolVar(&opts.Board.Coordinates, "coords", false, "label files and ranks")
+	flag.BoolVar(&opts.Board.SquareNumbers, "numbers", false, "number the playable squares")
+	flag.Parse()
+
+	input := io.Reader(os.Stdin)
+	if flag.NArg() > 0 {
+		f, err := os.Open(flag.Arg(0))
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		input = f
+	}
+	start, moves, err := readTranscript(input)
+	if err != nil {
+		log.Fatal(err)
+	}
+	opts.Start = start
+
+	out := io.Writer(os.Stdout)
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		out = f
+	}
+	if err := render.GIF(out, moves, opts); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func main() {
+	load := Load{}
+	flag.StringVar(&load.Addr, "server", "localhost:5000", "address of the server to load")
+	flag.IntVar(&load.Clients, "clients", 1000, "number of clients, paired into games, so even")
+	flag.Float64Var(&load.Rate, "rate", 1, "moves per second in each game")
+	flag.DurationVar(&load.Duration, "duration", 30*time.Second, "how long to play")
+	flag.DurationVar(&load.Ramp, "ramp", 5*time.Second, "time over which clients connect")
+	flag.IntVar(&load.MaxPlies, "max-plies", 200, "plies after which an unfinished game is abandoned")
+	flag.Int64Var(&load.Seed, "seed", time.Now().UnixNano(), "random seed for the moves")
+	flag.Parse()
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		cancel()
+	}()
+	stats := NewStats()
+	started := time.Now()
+	if err := load.Run(ctx, stats); err != nil {
+		log.Fatal(err)
+	}
+	stats.Report(os.Stdout, time.Since(started))
+}
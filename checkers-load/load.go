@@ -0,0 +1,317 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/batkinson/checkers-go/checkers"
+	"github.com/batkinson/checkers-go/checkers/client"
+	"io"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Load describes a run: Clients connections, an even number paired into
+// games, each game moving Rate times a second for Duration. Connections are opened evenly over
+// Ramp, and a game that reaches MaxPlies without a winner is abandoned for a
+// new one.
+type Load struct {
+	Addr     string
+	Clients  int
+	Rate     float64
+	Duration time.Duration
+	Ramp     time.Duration
+	MaxPlies int
+	Seed     int64
+}
+
+const (
+	ACKNOWLEDGED = "MOVE to OK"
+	BROADCAST    = "MOVE to opponent's STATUS MOVED"
+)
+
+// Stats collects what the clients measured. Latencies are kept for moves
+// being acknowledged and for the opponent hearing of them.
+type Stats struct {
+	lock      sync.Mutex
+	connected int
+	moves     int
+	games     int
+	latencies map[string][]time.Duration
+	errors    map[string]int
+}
+
+func NewStats() *Stats {
+	return &Stats{latencies: map[string][]time.Duration{}, errors: map[string]int{}}
+}
+
+func (stats *Stats) connect() {
+	stats.lock.Lock()
+	defer stats.lock.Unlock()
+	stats.connected++
+}
+
+func (stats *Stats) record(kind string, latency time.Duration) {
+	stats.lock.Lock()
+	defer stats.lock.Unlock()
+	if kind == ACKNOWLEDGED {
+		stats.moves++
+	}
+	stats.latencies[kind] = append(stats.latencies[kind], latency)
+}
+
+func (stats *Stats) game() {
+	stats.lock.Lock()
+	defer stats.lock.Unlock()
+	stats.games++
+}
+
+// fail counts an error by its protocol code, or as a connection error.
+func (stats *Stats) fail(err error) {
+	kind := "CONNECTION"
+	var reply *client.Error
+	if errors.As(err, &reply) {
+		kind = reply.Code
+	}
+	stats.lock.Lock()
+	defer stats.lock.Unlock()
+	stats.errors[kind]++
+}
+
+// percentile returns the latency that p percent of moves were at most.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(float64(len(sorted))*p/100+0.5) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+	return sorted[i]
+}
+
+// Report writes the totals for a run that took elapsed.
+func (stats *Stats) Report(w io.Writer, elapsed time.Duration) {
+	stats.lock.Lock()
+	defer stats.lock.Unlock()
+	fmt.Fprintf(w, "clients connected: %v\n", stats.connected)
+	fmt.Fprintf(w, "moves: %v in %v (%.1f/s)\n", stats.moves, elapsed.Round(time.Millisecond), float64(stats.moves)/elapsed.Seconds())
+	fmt.Fprintf(w, "games finished: %v\n", stats.games)
+	for _, kind := range []string{ACKNOWLEDGED, BROADCAST} {
+		sorted := append([]time.Duration{}, stats.latencies[kind]...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		fmt.Fprintf(w, "%v latency:", kind)
+		for _, p := range []float64{50, 90, 99, 100} {
+			fmt.Fprintf(w, " p%v %v", p, percentile(sorted, p))
+		}
+		fmt.Fprintln(w)
+	}
+	kinds := []string{}
+	for kind := range stats.errors {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	fmt.Fprintf(w, "errors: %v\n", len(kinds))
+	for _, kind := range kinds {
+		fmt.Fprintf(w, "  %v: %v\n", kind, stats.errors[kind])
+	}
+}
+
+// Validate checks that the load can be played.
+func (load Load) Validate() error {
+	switch {
+	case load.Clients < 2 || load.Clients%2 != 0:
+		return fmt.Errorf("expected an even number of clients, at least 2, got %v", load.Clients)
+	case load.Rate <= 0:
+		return fmt.Errorf("expected a positive rate, got %v", load.Rate)
+	}
+	return nil
+}
+
+// Run plays the load until its duration is up or ctx is done. It fails
+// without connecting if the load is invalid.
+func (load Load) Run(ctx context.Context, stats *Stats) error {
+	if err := load.Validate(); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, load.Duration)
+	defer cancel()
+	pairs := load.Clients / 2
+	var wg sync.WaitGroup
+	for i := 0; i < pairs; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if load.Ramp > 0 {
+				select {
+				case <-time.After(load.Ramp * time.Duration(i) / time.Duration(pairs)):
+				case <-ctx.Done():
+					return
+				}
+			}
+			p := &pair{load: load, stats: stats, rand: rand.New(rand.NewSource(load.Seed + int64(i)))}
+			p.run(ctx)
+		}(i)
+	}
+	wg.Wait()
+	return nil
+}
+
+// pair is two clients playing each other, game after game. It keeps its own
+// copy of the game, applying each move the server accepts. sent holds who
+// sent each ply and when, until the opponent hears of it.
+type pair struct {
+	load    Load
+	stats   *Stats
+	rand    *rand.Rand
+	clients [2]*client.Client
+	seats   map[checkers.Player]*client.Client
+	gameId  string
+	game    *checkers.Game
+	plies   int
+	lock    sync.Mutex
+	sent    map[ply]sending
+}
+
+// ply identifies a move by its game and its number within the game, as the
+// same move can be made more than once.
+type ply struct {
+	gameId string
+	number int
+}
+
+type sending struct {
+	mover *client.Client
+	at    time.Time
+}
+
+func (p *pair) run(ctx context.Context) {
+	defer p.close()
+	for i := range p.clients {
+		c, err := client.Dial(p.load.Addr)
+		if err != nil {
+			p.stats.fail(err)
+			return
+		}
+		p.clients[i] = c
+		go p.watch(c)
+		p.stats.connect()
+	}
+	if err := p.start(); err != nil {
+		p.stats.fail(err)
+		return
+	}
+	interval := time.Duration(float64(time.Second) / p.load.Rate)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		if err := p.step(); err != nil {
+			p.stats.fail(err)
+			if p.clients[0].Err() != nil || p.clients[1].Err() != nil {
+				return
+			}
+			if err := p.start(); err != nil {
+				p.stats.fail(err)
+				return
+			}
+		}
+	}
+}
+
+// watch times the moves c hears its opponent make, numbering the moves of
+// each game in the order they are heard.
+func (p *pair) watch(c *client.Client) {
+	heard := ply{}
+	for event := range c.Events {
+		if event.Type == client.GAME_ID {
+			heard = ply{event.GameId, 0}
+		}
+		if event.Type != client.MOVED {
+			continue
+		}
+		moved := heard
+		heard.number++
+		p.lock.Lock()
+		sent, ok := p.sent[moved]
+		ok = ok && sent.mover != c
+		if ok {
+			delete(p.sent, moved)
+		}
+		p.lock.Unlock()
+		if ok {
+			p.stats.record(BROADCAST, time.Since(sent.at))
+		}
+	}
+}
+
+// start begins a new game between the pair, leaving any game they were in.
+func (p *pair) start() error {
+	for _, c := range p.clients {
+		if c.GameId() != "" {
+			if err := c.Leave(); err != nil {
+				return err
+			}
+		}
+	}
+	id, err := p.clients[0].New()
+	if err != nil {
+		return err
+	}
+	if err := p.clients[1].Join(id); err != nil {
+		return err
+	}
+	p.seats = map[checkers.Player]*client.Client{}
+	for _, c := range p.clients {
+		p.seats[c.Player()] = c
+	}
+	if p.seats[checkers.BLACK_PLAYER] == nil || p.seats[checkers.RED_PLAYER] == nil {
+		return fmt.Errorf("expected a player of each colour in game %v", id)
+	}
+	p.gameId, p.game, p.plies = id, checkers.New(), 0
+	p.lock.Lock()
+	p.sent = map[ply]sending{}
+	p.lock.Unlock()
+	return nil
+}
+
+// step plays one random legal move, starting a new game once this one ends.
+func (p *pair) step() error {
+	moves := p.game.LegalMoves()
+	if len(moves) == 0 || p.game.Winner() != checkers.NO_PLAYER || p.plies >= p.load.MaxPlies {
+		if p.game.Winner() != checkers.NO_PLAYER {
+			p.stats.game()
+		}
+		return p.start()
+	}
+	move := moves[p.rand.Intn(len(moves))]
+	mover, sent := p.seats[p.game.Turn], time.Now()
+	p.lock.Lock()
+	p.sent[ply{p.gameId, p.plies}] = sending{mover, sent}
+	p.lock.Unlock()
+	if err := mover.Move(move); err != nil {
+		return err
+	}
+	p.stats.record(ACKNOWLEDGED, time.Since(sent))
+	p.game.Move(move.Src, move.Dst)
+	p.plies++
+	return nil
+}
+
+func (p *pair) close() {
+	for _, c := range p.clients {
+		if c != nil {
+			c.Quit()
+			c.Close()
+		}
+	}
+}
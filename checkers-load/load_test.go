@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"github.com/batkinson/checkers-go/checkers/server"
+	"io/ioutil"
+	"log"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	sorted := []time.Duration{}
+	for i := 1; i <= 100; i++ {
+		sorted = append(sorted, time.Duration(i)*time.Millisecond)
+	}
+	for p, expected := range map[float64]time.Duration{0: time.Millisecond, 50: 50 * time.Millisecond, 99: 99 * time.Millisecond, 100: 100 * time.Millisecond} {
+		if actual := percentile(sorted, p); actual != expected {
+			t.Errorf("expected p%v to be %v, got %v", p, expected, actual)
+		}
+	}
+	if actual := percentile(nil, 50); actual != 0 {
+		t.Errorf("expected no latency without moves, got %v", actual)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	srv := server.New(server.DefaultConfig())
+	srv.Logger = log.New(ioutil.Discard, "", 0)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(listener)
+	defer srv.Shutdown(context.Background())
+	load := Load{Addr: listener.Addr().String(), Clients: 6, Rate: 100, Duration: 500 * time.Millisecond, MaxPlies: 20, Seed: 1}
+	stats := NewStats()
+	if err := load.Run(context.Background(), stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.connected != 6 || stats.moves == 0 || len(stats.errors) != 0 {
+		t.Errorf("expected 6 clients to move without errors, got %v clients, %v moves, errors %v", stats.connected, stats.moves, stats.errors)
+	}
+	if len(stats.latencies[BROADCAST]) == 0 {
+		t.Errorf("expected opponents to hear of moves")
+	}
+	var out bytes.Buffer
+	stats.Report(&out, time.Second)
+	for _, expected := range []string{"clients connected: 6", "MOVE to OK latency: p50", "errors: 0"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected report to contain %q, got:\n%v", expected, out.String())
+		}
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	for _, load := range []Load{{Clients: 3, Rate: 1}, {Clients: 0, Rate: 1}, {Clients: 2, Rate: 0}} {
+		stats := NewStats()
+		if err := load.Run(context.Background(), stats); err == nil || stats.connected != 0 {
+			t.Errorf("expected %+v to be refused without connecting, got %v", load, err)
+		}
+	}
+}
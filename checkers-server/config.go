@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/batkinson/checkers-go/checkers/server"
+	"os"
+	"strings"
+)
+
+const ENV_PREFIX = "CHECKERS_"
+
+// define registers a flag for each setting, defaulting to the value in cfg,
+// and returns the path of the config file.
+func define(cfg *server.Config, fs *flag.FlagSet) (path *string) {
+	fs.StringVar(&cfg.Listen, "listen", cfg.Listen, "address to listen on")
+	fs.StringVar(&cfg.HTTP, "http", cfg.HTTP, "address to serve HTTP and WebSocket clients on, empty for none")
+	fs.IntVar(&cfg.ServerQueue, "server-queue", cfg.ServerQueue, "lobby commands queued before clients wait")
+	fs.IntVar(&cfg.ClientQueue, "client-queue", cfg.ClientQueue, "messages queued for each client, at least 1")
+	fs.IntVar(&cfg.GameIdLength, "game-id-length", cfg.GameIdLength, "characters in generated game ids")
+	fs.IntVar(&cfg.MaxSpectators, "max-spectators", cfg.MaxSpectators, "spectators allowed per game, 0 for no limit")
+	fs.IntVar(&cfg.MaxClients, "max-clients", cfg.MaxClients, "simultaneous connections allowed, 0 for no limit")
+	fs.DurationVar(&cfg.IdleTimeout, "idle-timeout", cfg.IdleTimeout, "disconnect clients silent for this long, 0 to never")
+	fs.DurationVar(&cfg.ResumeGrace, "resume-grace", cfg.ResumeGrace, "time a disconnected player has to RESUME before forfeiting, 0 to free the seat at once")
+	fs.StringVar(&cfg.SlowClients, "slow-clients", cfg.SlowClients, "what to do when a client's queue fills: drop, coalesce or disconnect")
+	fs.DurationVar(&cfg.WriteTimeout, "write-timeout", cfg.WriteTimeout, "disconnect clients that take this long to accept a message, 0 to wait forever")
+	fs.DurationVar(&cfg.HTTPTimeout, "http-timeout", cfg.HTTPTimeout, "close HTTP connections whose request takes this long to arrive, 0 to wait forever")
+	return fs.String("config", "", "JSON file of settings, keyed by flag name")
+}
+
+// envName is the environment variable that overrides a flag, such as
+// CHECKERS_IDLE_TIMEOUT for -idle-timeout.
+func envName(flagName string) string {
+	return ENV_PREFIX + strings.ToUpper(strings.Replace(flagName, "-", "_", -1))
+}
+
+func applyEnv(fs *flag.FlagSet, getenv func(string) string) (err error) {
+	fs.VisitAll(func(f *flag.Flag) {
+		if value := getenv(envName(f.Name)); value != "" && err == nil {
+			if setErr := fs.Set(f.Name, value); setErr != nil {
+				err = fmt.Errorf("invalid %v: %v", envName(f.Name), setErr)
+			}
+		}
+	})
+	return err
+}
+
+func applyFile(fs *flag.FlagSet, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	settings := map[string]interface{}{}
+	decoder := json.NewDecoder(file)
+	decoder.UseNumber()
+	if err := decoder.Decode(&settings); err != nil {
+		return fmt.Errorf("invalid config file %v: %v", path, err)
+	}
+	for name, value := range settings {
+		if fs.Lookup(name) == nil || name == "config" {
+			return fmt.Errorf("invalid config file %v: unknown setting %v", path, name)
+		}
+		if err := fs.Set(name, fmt.Sprint(value)); err != nil {
+			return fmt.Errorf("invalid config file %v: %v: %v", path, name, err)
+		}
+	}
+	return nil
+}
+
+// loadConfig builds the configuration from, in increasing priority, the
+// defaults, the config file, CHECKERS_* environment variables and args.
+func loadConfig(args []string, getenv func(string) string) (server.Config, error) {
+	cfg := server.DefaultConfig()
+	fs := flag.NewFlagSet("checkers-server", flag.ContinueOnError)
+	path := define(&cfg, fs)
+	if err := applyEnv(fs, getenv); err != nil {
+		return cfg, err
+	}
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+	if *path != "" {
+		if err := applyFile(fs, *path); err != nil {
+			return cfg, err
+		}
+		if err := applyEnv(fs, getenv); err != nil {
+			return cfg, err
+		}
+		if err := fs.Parse(args); err != nil {
+			return cfg, err
+		}
+	}
+	if fs.NArg() > 0 {
+		return cfg, fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+	return cfg, cfg.Validate()
+}
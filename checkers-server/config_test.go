@@ -0,0 +1,86 @@
+package main
+
+import (
+	"github.com/batkinson/checkers-go/checkers/server"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func noEnv(string) string {
+	return ""
+}
+
+func TestLoadConfigDefaults(t *testing.T) {
+	cfg, err := loadConfig([]string{}, noEnv)
+	if err != nil || cfg != server.DefaultConfig() {
+		t.Errorf("expected defaults, got %+v, %v", cfg, err)
+	}
+}
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkers-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "server.json")
+	settings := `{"listen": ":6000", "client-queue": 32, "max-spectators": 2, "idle-timeout": "5m"}`
+	if err := ioutil.WriteFile(path, []byte(settings), 0644); err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{
+		"CHECKERS_CONFIG":         path,
+		"CHECKERS_MAX_SPECTATORS": "4",
+		"CHECKERS_LISTEN":         ":7000",
+	}
+	cfg, err := loadConfig([]string{"-listen", ":8000"}, func(name string) string { return env[name] })
+	if err != nil {
+		t.Fatalf("expected config to load: %v", err)
+	}
+	expected := server.DefaultConfig()
+	expected.Listen = ":8000"
+	expected.ClientQueue = 32
+	expected.MaxSpectators = 4
+	expected.IdleTimeout = 5 * time.Minute
+	if cfg != expected {
+		t.Errorf("expected %+v, got %+v", expected, cfg)
+	}
+}
+
+func TestLoadConfigNoSpectatorLimit(t *testing.T) {
+	cfg, err := loadConfig([]string{"-max-spectators", "0"}, noEnv)
+	if err != nil || cfg.MaxSpectators != 0 {
+		t.Errorf("expected 0 to be accepted as no limit, got %v, %v", cfg.MaxSpectators, err)
+	}
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkers-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	unknown := filepath.Join(dir, "unknown.json")
+	ioutil.WriteFile(unknown, []byte(`{"colour": "blue"}`), 0644)
+	for _, args := range [][]string{
+		{"-game-id-length", "0"},
+		{"-client-queue", "many"},
+		{"-client-queue", "0"},
+		{"-max-clients", "-1"},
+		{"-http-timeout", "-1s"},
+		{"-slow-clients", "wait"},
+		{"-config", unknown},
+		{"-config", filepath.Join(dir, "missing.json")},
+		{"extra"},
+	} {
+		if _, err := loadConfig(args, noEnv); err == nil {
+			t.Errorf("expected %v to be rejected", args)
+		}
+	}
+	if _, err := loadConfig([]string{}, func(name string) string { return "soon" }); err == nil {
+		t.Errorf("expected invalid environment to be rejected")
+	}
+}
@@ -0,0 +1,62 @@
+// Package ai provides computer players for checkers games.
+package ai
+
+import (
+	"github.com/batkinson/checkers-go/checkers"
+	"math/rand"
+)
+
+const (
+	MAN_VALUE     = 100
+	KING_VALUE    = 160
+	WIN_VALUE     = 100000
+	ADVANCE_BONUS = 2
+)
+
+// Engine chooses moves for a player. ChooseMove returns checkers.NO_MOVE when
+// the player has no legal move.
+type Engine interface {
+	ChooseMove(game *checkers.Game, player checkers.Player) checkers.Move
+}
+
+// Evaluate scores the position from player's point of view using material
+// and the advancement of men toward the king row.
+func Evaluate(game *checkers.Game, player checkers.Player) int {
+	score := 0
+	for pos, piece := range game.Pieces {
+		value := MAN_VALUE
+		if piece.King {
+			value = KING_VALUE
+		} else if piece.Player == checkers.BLACK_PLAYER {
+			value += pos.Y * ADVANCE_BONUS
+		} else {
+			value += (checkers.BOARD_DIM - 1 - pos.Y) * ADVANCE_BONUS
+		}
+		if piece.Player == player {
+			score += value
+		} else {
+			score -= value
+		}
+	}
+	return score
+}
+
+// Random plays a uniformly random legal move.
+type Random struct {
+	Rand *rand.Rand
+}
+
+func NewRandom(seed int64) *Random {
+	return &Random{rand.New(rand.NewSource(seed))}
+}
+
+func (r *Random) ChooseMove(game *checkers.Game, player checkers.Player) checkers.Move {
+	if !game.TurnIs(player) {
+		return checkers.NO_MOVE
+	}
+	moves := game.LegalMoves()
+	if len(moves) == 0 {
+		return checkers.NO_MOVE
+	}
+	return moves[r.Rand.Intn(len(moves))]
+}
@@ -0,0 +1,99 @@
+package ai
+
+import (
+	"github.com/batkinson/checkers-go/checkers"
+	"testing"
+	"time"
+)
+
+func TestAlphaBetaTakesFreePiece(t *testing.T) {
+	game, _ := checkers.Parse("********|********|***b****|********|*r******|********|*****r**|********")
+	game.Pieces[checkers.Pos{X: 4, Y: 3}] = checkers.Piece{Player: checkers.RED_PLAYER}
+	move := NewAlphaBeta(4, 1).ChooseMove(game, checkers.BLACK_PLAYER)
+	if move.Src != (checkers.Pos{X: 3, Y: 2}) || move.Dst != (checkers.Pos{X: 5, Y: 4}) {
+		t.Errorf("expected black to capture, got %v", move)
+	}
+}
+
+func TestSelfPlayFinishes(t *testing.T) {
+	game := checkers.New()
+	engines := map[checkers.Player]Engine{
+		checkers.BLACK_PLAYER: NewAlphaBeta(2, 1),
+		checkers.RED_PLAYER:   NewRandom(1),
+	}
+	for i := 0; i < 400; i++ {
+		move := engines[game.Turn].ChooseMove(game, game.Turn)
+		if move == checkers.NO_MOVE {
+			break
+		}
+		if _, err := game.Move(move.Src, move.Dst); err != nil {
+			t.Fatalf("engine chose illegal move %v: %v", move, err)
+		}
+	}
+	if game.Winner() != checkers.BLACK_PLAYER {
+		t.Errorf("expected search to beat random play, got %v", game)
+	}
+	if NewRandom(1).ChooseMove(game, game.Turn) != checkers.NO_MOVE {
+		t.Errorf("expected no move once the game is over")
+	}
+}
+
+func TestMCTSTakesWinningCapture(t *testing.T) {
+	game, _ := checkers.Parse("********|********|***b****|****r***|********|********|********|********")
+	for _, policy := range []int{RANDOM_PLAYOUT, HEURISTIC_PLAYOUT} {
+		player := NewMCTS(200, 1)
+		player.Policy = policy
+		game.Pieces[checkers.Pos{X: 0, Y: 7}] = checkers.Piece{Player: checkers.RED_PLAYER}
+		move := player.ChooseMove(game, checkers.BLACK_PLAYER)
+		if move != (checkers.Move{Src: checkers.Pos{X: 3, Y: 2}, Dst: checkers.Pos{X: 5, Y: 4}}) {
+			t.Errorf("expected capture with policy %v, got %v", policy, move)
+		}
+	}
+}
+
+func TestMCTSBudget(t *testing.T) {
+	player := NewMCTS(0, 1)
+	player.Budget = 50 * time.Millisecond
+	start := time.Now()
+	move := player.ChooseMove(checkers.New(), checkers.BLACK_PLAYER)
+	if move == checkers.NO_MOVE {
+		t.Errorf("expected a move from the opening position")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected search to respect its budget, took %v", elapsed)
+	}
+	if player.ChooseMove(checkers.New(), checkers.RED_PLAYER) != checkers.NO_MOVE {
+		t.Errorf("expected no move when it is not the player's turn")
+	}
+}
+
+func TestMCTSTinyBudget(t *testing.T) {
+	player := NewMCTS(0, 1)
+	player.Budget = time.Nanosecond
+	if move := player.ChooseMove(checkers.New(), checkers.BLACK_PLAYER); move == checkers.NO_MOVE {
+		t.Errorf("expected a move when the budget runs out at once")
+	}
+	if move := (&MCTS{}).ChooseMove(checkers.New(), checkers.BLACK_PLAYER); move == checkers.NO_MOVE {
+		t.Errorf("expected a zero MCTS to choose a move")
+	}
+}
+
+func TestMCTSBeatsRandom(t *testing.T) {
+	game := checkers.New()
+	engines := map[checkers.Player]Engine{
+		checkers.BLACK_PLAYER: NewRandom(3),
+		checkers.RED_PLAYER:   NewMCTS(300, 3),
+	}
+	for i := 0; i < 400; i++ {
+		move := engines[game.Turn].ChooseMove(game, game.Turn)
+		if move == checkers.NO_MOVE {
+			break
+		}
+		if _, err := game.Move(move.Src, move.Dst); err != nil {
+			t.Fatalf("engine chose illegal move %v: %v", move, err)
+		}
+	}
+	if game.Winner() != checkers.RED_PLAYER {
+		t.Errorf("expected tree search to beat random play, got %v", game)
+	}
+}
@@ -0,0 +1,86 @@
+package ai
+
+import (
+	"github.com/batkinson/checkers-go/checkers"
+	"math/rand"
+)
+
+const DEFAULT_DEPTH = 6
+
+// AlphaBeta searches Depth plies ahead with alpha-beta pruning. Each jump of
+// a multi-jump counts as a ply. When Rand is set, ties between equally scored
+// moves are broken at random so repeated games vary.
+type AlphaBeta struct {
+	Depth int
+	Rand  *rand.Rand
+}
+
+func NewAlphaBeta(depth int, seed int64) *AlphaBeta {
+	return &AlphaBeta{depth, rand.New(rand.NewSource(seed))}
+}
+
+func (ab *AlphaBeta) ChooseMove(game *checkers.Game, player checkers.Player) checkers.Move {
+	if !game.TurnIs(player) {
+		return checkers.NO_MOVE
+	}
+	moves := game.LegalMoves()
+	if len(moves) == 0 {
+		return checkers.NO_MOVE
+	}
+	depth := ab.Depth
+	if depth < 1 {
+		depth = DEFAULT_DEPTH
+	}
+	best := []checkers.Move{}
+	bestScore := -WIN_VALUE * 2
+	for _, move := range moves {
+		next := game.Clone()
+		next.Move(move.Src, move.Dst)
+		score := ab.search(next, player, depth-1, -WIN_VALUE*2, WIN_VALUE*2)
+		if score > bestScore {
+			bestScore = score
+			best = []checkers.Move{move}
+		} else if score == bestScore {
+			best = append(best, move)
+		}
+	}
+	if ab.Rand == nil {
+		return best[0]
+	}
+	return best[ab.Rand.Intn(len(best))]
+}
+
+func (ab *AlphaBeta) search(game *checkers.Game, player checkers.Player, depth, alpha, beta int) int {
+	moves := game.LegalMoves()
+	if len(moves) == 0 {
+		switch game.Winner() {
+		case player:
+			return WIN_VALUE + depth
+		case checkers.NO_PLAYER:
+			return 0
+		default:
+			return -WIN_VALUE - depth
+		}
+	}
+	if depth <= 0 {
+		return Evaluate(game, player)
+	}
+	maximizing := game.TurnIs(player)
+	for _, move := range moves {
+		next := game.Clone()
+		next.Move(move.Src, move.Dst)
+		score := ab.search(next, player, depth-1, alpha, beta)
+		if maximizing && score > alpha {
+			alpha = score
+		} else if !maximizing && score < beta {
+			beta = score
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+	if maximizing {
+		return alpha
+	}
+	return beta
+}
@@ -0,0 +1,178 @@
+package ai
+
+import (
+	"github.com/batkinson/checkers-go/checkers"
+	"math"
+	"math/rand"
+	"time"
+)
+
+const (
+	RANDOM_PLAYOUT = iota
+	HEURISTIC_PLAYOUT
+)
+
+const (
+	DEFAULT_EXPLORATION   = math.Sqrt2
+	DEFAULT_PLAYOUTS      = 2000
+	DEFAULT_PLAYOUT_DEPTH = 120
+)
+
+// MCTS is a Monte Carlo tree search player using UCT selection. The search
+// stops after Playouts iterations or once Budget has elapsed, whichever comes
+// first, but always runs at least one; when neither is set it runs
+// DEFAULT_PLAYOUTS iterations. Playouts that reach PlayoutDepth plies without
+// a winner are scored by Evaluate. A nil Rand is seeded from the clock.
+type MCTS struct {
+	Exploration  float64
+	Playouts     int
+	PlayoutDepth int
+	Budget       time.Duration
+	Policy       int
+	Rand         *rand.Rand
+}
+
+func NewMCTS(playouts int, seed int64) *MCTS {
+	return &MCTS{
+		DEFAULT_EXPLORATION,
+		playouts,
+		DEFAULT_PLAYOUT_DEPTH,
+		0,
+		RANDOM_PLAYOUT,
+		rand.New(rand.NewSource(seed)),
+	}
+}
+
+type node struct {
+	game     *checkers.Game
+	move     checkers.Move
+	mover    checkers.Player
+	parent   *node
+	children []*node
+	untried  []checkers.Move
+	visits   float64
+	wins     float64
+}
+
+func newNode(game *checkers.Game, move checkers.Move, mover checkers.Player, parent *node) *node {
+	return &node{game, move, mover, parent, []*node{}, game.LegalMoves(), 0, 0}
+}
+
+func (n *node) uct(exploration float64) *node {
+	var best *node
+	bestScore := math.Inf(-1)
+	for _, child := range n.children {
+		score := child.wins/child.visits + exploration*math.Sqrt(math.Log(n.visits)/child.visits)
+		if score > bestScore {
+			best, bestScore = child, score
+		}
+	}
+	return best
+}
+
+func (m *MCTS) ChooseMove(game *checkers.Game, player checkers.Player) checkers.Move {
+	if !game.TurnIs(player) {
+		return checkers.NO_MOVE
+	}
+	root := newNode(game.Clone(), checkers.NO_MOVE, checkers.NO_PLAYER, nil)
+	if len(root.untried) == 0 {
+		return checkers.NO_MOVE
+	}
+	if len(root.untried) == 1 {
+		return root.untried[0]
+	}
+	rng := m.Rand
+	if rng == nil {
+		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	playouts, deadline := m.Playouts, time.Time{}
+	if m.Budget > 0 {
+		deadline = time.Now().Add(m.Budget)
+	} else if playouts <= 0 {
+		playouts = DEFAULT_PLAYOUTS
+	}
+	for i := 0; playouts <= 0 || i < playouts; i++ {
+		if i > 0 && !deadline.IsZero() && time.Now().After(deadline) {
+			break
+		}
+		n := root
+		for len(n.untried) == 0 && len(n.children) > 0 {
+			n = n.uct(m.Exploration)
+		}
+		if len(n.untried) > 0 {
+			j := rng.Intn(len(n.untried))
+			move := n.untried[j]
+			n.untried = append(n.untried[:j], n.untried[j+1:]...)
+			next := n.game.Clone()
+			next.Move(move.Src, move.Dst)
+			child := newNode(next, move, n.game.Turn, n)
+			n.children = append(n.children, child)
+			n = child
+		}
+		scores := m.playout(n.game.Clone(), rng)
+		for ; n != nil; n = n.parent {
+			n.visits++
+			n.wins += scores[n.mover]
+		}
+	}
+	best := root.children[0]
+	for _, child := range root.children {
+		if child.visits > best.visits {
+			best = child
+		}
+	}
+	return best.move
+}
+
+// playout plays the game out and returns the score each player earned: 1 for
+// a win, 0 for a loss and 0.5 for a draw.
+func (m *MCTS) playout(game *checkers.Game, rng *rand.Rand) map[checkers.Player]float64 {
+	depth := m.PlayoutDepth
+	if depth <= 0 {
+		depth = DEFAULT_PLAYOUT_DEPTH
+	}
+	for i := 0; i < depth; i++ {
+		moves := game.LegalMoves()
+		if len(moves) == 0 {
+			break
+		}
+		var move checkers.Move
+		if m.Policy == HEURISTIC_PLAYOUT {
+			move = m.greedy(game, moves, rng)
+		} else {
+			move = moves[rng.Intn(len(moves))]
+		}
+		game.Move(move.Src, move.Dst)
+	}
+	winner := game.Winner()
+	if winner == checkers.NO_PLAYER {
+		switch score := Evaluate(game, checkers.BLACK_PLAYER); {
+		case score > 0:
+			winner = checkers.BLACK_PLAYER
+		case score < 0:
+			winner = checkers.RED_PLAYER
+		}
+	}
+	if winner == checkers.NO_PLAYER {
+		return map[checkers.Player]float64{checkers.BLACK_PLAYER: 0.5, checkers.RED_PLAYER: 0.5}
+	}
+	return map[checkers.Player]float64{winner: 1, checkers.Opponents[winner]: 0}
+}
+
+// greedy picks the move that scores best one ply ahead, breaking ties at
+// random.
+func (m *MCTS) greedy(game *checkers.Game, moves []checkers.Move, rng *rand.Rand) checkers.Move {
+	best := []checkers.Move{}
+	bestScore := math.MinInt32
+	for _, move := range moves {
+		next := game.Clone()
+		next.Move(move.Src, move.Dst)
+		score := Evaluate(next, game.Turn)
+		if score > bestScore {
+			best, bestScore = []checkers.Move{move}, score
+		} else if score == bestScore {
+			best = append(best, move)
+		}
+	}
+	return best[rng.Intn(len(best))]
+}
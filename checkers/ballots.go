@@ -0,0 +1,84 @@
+package checkers
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// Ballot is a three-move opening: black's first move, red's reply and black's
+// second move. Ballots are numbered from 1 in notation order, by black's first
+// move, then red's reply, then black's second move.
+type Ballot struct {
+	Number int
+	Moves  [3]Move
+}
+
+var NO_BALLOT = Ballot{}
+
+var ErrNoSuchBallot = errors.New("no such ballot")
+
+// Ballots lists all 302 three-move openings reachable from the standard
+// start. It is not the ACF deck, which bars unsound openings and numbers the
+// rest differently, so ballot numbers do not match published ballot cards.
+var Ballots []Ballot
+
+func generateBallots() []Ballot {
+	ballots := []Ballot{}
+	start := New()
+	for _, first := range start.LegalMoves() {
+		afterFirst := start.Clone()
+		afterFirst.Move(first.Src, first.Dst)
+		for _, reply := range afterFirst.LegalMoves() {
+			afterReply := afterFirst.Clone()
+			afterReply.Move(reply.Src, reply.Dst)
+			for _, second := range afterReply.LegalMoves() {
+				ballots = append(ballots, Ballot{len(ballots) + 1, [3]Move{first, reply, second}})
+			}
+		}
+	}
+	return ballots
+}
+
+func (ballot Ballot) String() string {
+	names := make([]string, len(ballot.Moves))
+	for i, move := range ballot.Moves {
+		names[i] = move.String()
+	}
+	return strings.Join(names, " ")
+}
+
+// FindBallot looks up a ballot by number or by its moves, such as
+// "9-13 21-17 5-9".
+func FindBallot(name string) (Ballot, error) {
+	if number, err := strconv.Atoi(name); err == nil {
+		if number < 1 || number > len(Ballots) {
+			return NO_BALLOT, fmt.Errorf("%w: %v", ErrNoSuchBallot, name)
+		}
+		return Ballots[number-1], nil
+	}
+	name = strings.Join(strings.Fields(name), " ")
+	for _, ballot := range Ballots {
+		if ballot.String() == name {
+			return ballot, nil
+		}
+	}
+	return NO_BALLOT, fmt.Errorf("%w: %v", ErrNoSuchBallot, name)
+}
+
+func RandomBallot(rng *rand.Rand) Ballot {
+	return Ballots[rng.Intn(len(Ballots))]
+}
+
+// NewBallot returns a game with the ballot's moves already played.
+func NewBallot(ballot Ballot) (*Game, error) {
+	game := New()
+	for _, move := range ballot.Moves {
+		if _, err := game.Move(move.Src, move.Dst); err != nil {
+			return nil, err
+		}
+	}
+	return game, nil
+}
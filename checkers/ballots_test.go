@@ -0,0 +1,48 @@
+package checkers
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestBallots(t *testing.T) {
+	if len(Ballots) != 302 {
+		t.Fatalf("expected 302 ballots, got %v", len(Ballots))
+	}
+	for number, moves := range map[int]string{1: "9-13 21-17 5-9", 2: "9-13 21-17 6-9", 100: "10-14 22-18 11-15", 302: "12-16 24-20 16-19"} {
+		if ballot, _ := FindBallot(fmt.Sprint(number)); ballot.String() != moves {
+			t.Errorf("expected ballot %v to be %v, got %v", number, moves, ballot)
+		}
+	}
+	seen := map[string]bool{}
+	for i, ballot := range Ballots {
+		if ballot.Number != i+1 {
+			t.Errorf("expected ballot %v to be numbered %v", ballot, i+1)
+		}
+		if seen[ballot.String()] {
+			t.Errorf("duplicate ballot %v", ballot)
+		}
+		seen[ballot.String()] = true
+		if _, err := NewBallot(ballot); err != nil {
+			t.Errorf("expected ballot %v to be playable: %v", ballot, err)
+		}
+	}
+	ballot, err := FindBallot("9-13  21-17 5-9")
+	if err != nil {
+		t.Fatalf("expected to find ballot by moves: %v", err)
+	}
+	if byNumber, _ := FindBallot(fmt.Sprint(ballot.Number)); byNumber != ballot {
+		t.Errorf("expected to find ballot %v by number, got %v", ballot, byNumber)
+	}
+	game, _ := NewBallot(ballot)
+	expected := "*b*b*b*b|**b*b*b*|*b*b*b*b|b*******|*r******|**r*r*r*|*r*r*r*r|r*r*r*r*"
+	if game.String() != expected || !game.TurnIs(RED_PLAYER) {
+		t.Errorf("expected %v with red to move, got %v", expected, game)
+	}
+	for _, name := range []string{"0", "100000", "9-13 21-17", "1-5 5-9 9-13"} {
+		if _, err := FindBallot(name); !errors.Is(err, ErrNoSuchBallot) {
+			t.Errorf("expected %v to be rejected, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+// Package book builds and queries opening books of weighted moves keyed by
+// board position.
+package book
+
+import (
+	"errors"
+	"fmt"
+	"github.com/batkinson/checkers-go/checkers"
+	"github.com/batkinson/checkers-go/checkers/ai"
+	"github.com/batkinson/checkers-go/checkers/pdn"
+	"io"
+	"math/rand"
+	"os"
+	"sort"
+)
+
+const (
+	DEFAULT_PLIES  = 16
+	MAX_GAME_PLIES = 400
+)
+
+type Entry struct {
+	Move   checkers.Move
+	Weight uint32
+}
+
+type Book struct {
+	Positions map[checkers.Snapshot][]Entry
+}
+
+func New() *Book {
+	return &Book{make(map[checkers.Snapshot][]Entry)}
+}
+
+// Add records move from the position in game, adding weight to any existing
+// entry for the same move.
+func (book *Book) Add(game *checkers.Game, move checkers.Move, weight uint32) error {
+	key, err := game.Snapshot()
+	if err != nil {
+		return err
+	}
+	entries := book.Positions[key]
+	for i := range entries {
+		if entries[i].Move == move {
+			entries[i].Weight += weight
+			return nil
+		}
+	}
+	book.Positions[key] = append(entries, Entry{move, weight})
+	return nil
+}
+
+// Moves returns the book moves for game that are legal in the position.
+func (book *Book) Moves(game *checkers.Game) []Entry {
+	result := []Entry{}
+	key, err := game.Snapshot()
+	if err != nil {
+		return result
+	}
+	for _, entry := range book.Positions[key] {
+		if game.ValidMove(entry.Move.Src, entry.Move.Dst) && game.TurnIs(game.Pieces[entry.Move.Src].Player) {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+// Choose picks a book move at random, in proportion to the entry weights.
+func (book *Book) Choose(game *checkers.Game, rng *rand.Rand) (checkers.Move, bool) {
+	entries := book.Moves(game)
+	total := uint64(0)
+	for _, entry := range entries {
+		total += uint64(entry.Weight)
+	}
+	if total == 0 {
+		return checkers.NO_MOVE, false
+	}
+	pick := uint64(rng.Int63n(int64(total)))
+	for _, entry := range entries {
+		if pick < uint64(entry.Weight) {
+			return entry.Move, true
+		}
+		pick -= uint64(entry.Weight)
+	}
+	return checkers.NO_MOVE, false
+}
+
+// AddLine replays moves from the starting position, recording the first plies
+// of them.
+func (book *Book) AddLine(moves []checkers.Move, plies int) error {
+	game := checkers.New()
+	for i, move := range moves {
+		if i >= plies {
+			break
+		}
+		if err := book.Add(game, move, 1); err != nil {
+			return err
+		}
+		if _, err := game.Move(move.Src, move.Dst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FromPDN builds a book from the first plies of every game in r. Games that
+// start from a set-up position are skipped, and games that cannot be replayed
+// are left out and reported in skipped.
+func FromPDN(r io.Reader, plies int) (book *Book, skipped []error, err error) {
+	games, err := pdn.Read(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	book = New()
+	for i, record := range games {
+		if _, setup := record.Tags["FEN"]; setup {
+			continue
+		}
+		if err := book.addRecord(record, plies); err != nil {
+			skipped = append(skipped, errors.New(fmt.Sprintf("game %v: %v", i+1, err)))
+		}
+	}
+	return book, skipped, nil
+}
+
+// addRecord adds the first plies of a game, adding nothing unless they all
+// replay.
+func (book *Book) addRecord(record *pdn.Game, plies int) error {
+	game := checkers.New()
+	line := []checkers.Move{}
+	for _, text := range record.Moves {
+		steps, err := pdn.Apply(game, text)
+		if err != nil {
+			return err
+		}
+		line = append(line, steps...)
+		if len(line) >= plies {
+			break
+		}
+	}
+	return book.AddLine(line, plies)
+}
+
+// FromSelfPlay builds a book from games the engine plays against itself,
+// recording the first plies of each.
+func FromSelfPlay(engine ai.Engine, games, plies int) (*Book, error) {
+	book := New()
+	for i := 0; i < games; i++ {
+		game := checkers.New()
+		line := []checkers.Move{}
+		for len(line) < plies && len(line) < MAX_GAME_PLIES {
+			move := engine.ChooseMove(game, game.Turn)
+			if move == checkers.NO_MOVE {
+				break
+			}
+			if _, err := game.Move(move.Src, move.Dst); err != nil {
+				return nil, err
+			}
+			line = append(line, move)
+		}
+		if err := book.AddLine(line, plies); err != nil {
+			return nil, err
+		}
+	}
+	return book, nil
+}
+
+func (book *Book) sortedKeys() []checkers.Snapshot {
+	keys := make([]checkers.Snapshot, 0, len(book.Positions))
+	for key := range book.Positions {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		aBlack, aRed, aKings := keys[i].Masks()
+		bBlack, bRed, bKings := keys[j].Masks()
+		if aBlack != bBlack {
+			return aBlack < bBlack
+		}
+		if aRed != bRed {
+			return aRed < bRed
+		}
+		if aKings != bKings {
+			return aKings < bKings
+		}
+		return keys[i].Turn() == checkers.BLACK_PLAYER && keys[j].Turn() != checkers.BLACK_PLAYER
+	})
+	return keys
+}
+
+func Load(path string) (*Book, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+func (book *Book) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = book.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Player plays book moves while the position is in the book and defers to
+// Engine once it leaves it.
+type Player struct {
+	Book   *Book
+	Engine ai.Engine
+	Rand   *rand.Rand
+}
+
+func (p *Player) ChooseMove(game *checkers.Game, player checkers.Player) checkers.Move {
+	if game.TurnIs(player) {
+		if move, ok := p.Book.Choose(game, p.Rand); ok {
+			return move
+		}
+	}
+	return p.Engine.ChooseMove(game, player)
+}
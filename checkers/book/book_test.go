@@ -0,0 +1,121 @@
+package book
+
+import (
+	"bytes"
+	"github.com/batkinson/checkers-go/checkers"
+	"github.com/batkinson/checkers-go/checkers/ai"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+const games = `1. 11-15 23-19 2. 8-11 22-17 *
+1. 11-15 23-19 2. 9-14 22-17 *
+1. 11-15 22-18 2. 15x22 25x18 *
+1. 9-13 22-18 *
+`
+
+func TestFromPDN(t *testing.T) {
+	book, skipped, err := FromPDN(strings.NewReader(games), DEFAULT_PLIES)
+	if err != nil || len(skipped) != 0 {
+		t.Fatalf("expected book to build: %v %v", err, skipped)
+	}
+	entries := book.Moves(checkers.New())
+	weights := map[string]uint32{}
+	for _, entry := range entries {
+		weights[entry.Move.String()] = entry.Weight
+	}
+	if len(entries) != 2 || weights["11-15"] != 3 || weights["9-13"] != 1 {
+		t.Errorf("expected 11-15 (3) and 9-13 (1) from the start, got %v", entries)
+	}
+	game := checkers.New()
+	game.Move(checkers.SquarePos(11), checkers.SquarePos(15))
+	if len(book.Moves(game)) != 2 {
+		t.Errorf("expected two replies to 11-15, got %v", book.Moves(game))
+	}
+	game.Move(checkers.SquarePos(22), checkers.SquarePos(18))
+	entries = book.Moves(game)
+	if len(entries) != 1 || entries[0].Move.String() != "15x22" {
+		t.Errorf("expected capture reply, got %v", entries)
+	}
+}
+
+func TestFromPDNSkipsBadGames(t *testing.T) {
+	bad := "1. 11-15 23-19 2. 15-10 22-17 *\n" + games
+	book, skipped, err := FromPDN(strings.NewReader(bad), DEFAULT_PLIES)
+	if err != nil {
+		t.Fatalf("expected book to build: %v", err)
+	}
+	if len(skipped) != 1 || !strings.HasPrefix(skipped[0].Error(), "game 1:") {
+		t.Errorf("expected the first game to be skipped, got %v", skipped)
+	}
+	for _, entry := range book.Moves(checkers.New()) {
+		if entry.Move.String() == "11-15" && entry.Weight != 3 {
+			t.Errorf("expected nothing from the bad game, got 11-15 (%v)", entry.Weight)
+		}
+	}
+}
+
+func TestChoose(t *testing.T) {
+	book := New()
+	game := checkers.New()
+	heavy := checkers.Move{Src: checkers.SquarePos(11), Dst: checkers.SquarePos(15)}
+	light := checkers.Move{Src: checkers.SquarePos(9), Dst: checkers.SquarePos(13)}
+	book.Add(game, heavy, 9)
+	book.Add(game, light, 1)
+	book.Add(game, checkers.Move{Src: checkers.SquarePos(22), Dst: checkers.SquarePos(18)}, 100)
+	rng := rand.New(rand.NewSource(1))
+	counts := map[checkers.Move]int{}
+	for i := 0; i < 1000; i++ {
+		move, ok := book.Choose(game, rng)
+		if !ok {
+			t.Fatalf("expected a book move")
+		}
+		counts[move]++
+	}
+	if counts[heavy]+counts[light] != 1000 {
+		t.Errorf("expected only legal book moves to be chosen, got %v", counts)
+	}
+	if counts[heavy] < 800 || counts[light] < 50 {
+		t.Errorf("expected choices in proportion to weight, got %v", counts)
+	}
+	game.Move(heavy.Src, heavy.Dst)
+	if _, ok := book.Choose(game, rng); ok {
+		t.Errorf("expected no book move out of book")
+	}
+}
+
+func TestReadWrite(t *testing.T) {
+	book, err := FromSelfPlay(ai.NewRandom(7), 20, 8)
+	if err != nil {
+		t.Fatalf("expected self-play book to build: %v", err)
+	}
+	if len(book.Positions) < 8 {
+		t.Errorf("expected self-play to produce varied positions, got %v", len(book.Positions))
+	}
+	var buf bytes.Buffer
+	if err := book.Write(&buf); err != nil {
+		t.Fatalf("expected book to write: %v", err)
+	}
+	loaded, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("expected book to read: %v", err)
+	}
+	if len(loaded.Positions) != len(book.Positions) {
+		t.Fatalf("expected %v positions, got %v", len(book.Positions), len(loaded.Positions))
+	}
+	for key, entries := range book.Positions {
+		if len(loaded.Positions[key]) != len(entries) {
+			t.Errorf("expected %v entries for %v, got %v", len(entries), key, loaded.Positions[key])
+			continue
+		}
+		for i, entry := range entries {
+			if loaded.Positions[key][i] != entry {
+				t.Errorf("expected entry %v, got %v", entry, loaded.Positions[key][i])
+			}
+		}
+	}
+	if _, err := Read(strings.NewReader("nope")); err == nil {
+		t.Errorf("expected invalid book to fail")
+	}
+}
@@ -0,0 +1,110 @@
+package book
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/batkinson/checkers-go/checkers"
+	"io"
+)
+
+// The on-disk format is a header followed by one record per position, sorted
+// by key. All integers are little-endian.
+//
+//	header:   magic "CKBK", version uint8, position count uint32
+//	position: black uint32, red uint32, kings uint32, turn uint8,
+//	          jumping square uint8 (0 for none), entry count uint8, entries
+//	entry:    source square uint8, destination square uint8, weight uint32
+const (
+	MAGIC   = "CKBK"
+	VERSION = 2
+)
+
+const (
+	blackTurn = 0
+	redTurn   = 1
+)
+
+func (book *Book) Write(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	out.WriteString(MAGIC)
+	out.WriteByte(VERSION)
+	binary.Write(out, binary.LittleEndian, uint32(len(book.Positions)))
+	for _, key := range book.sortedKeys() {
+		entries := book.Positions[key]
+		if len(entries) > 255 {
+			return errors.New(fmt.Sprintf("too many moves for position: %v", len(entries)))
+		}
+		turn := byte(blackTurn)
+		if key.Turn() == checkers.RED_PLAYER {
+			turn = redTurn
+		}
+		black, red, kings := key.Masks()
+		binary.Write(out, binary.LittleEndian, []uint32{black, red, kings})
+		out.WriteByte(turn)
+		out.WriteByte(byte(checkers.Square(key.Jumper())))
+		out.WriteByte(byte(len(entries)))
+		for _, entry := range entries {
+			out.WriteByte(byte(checkers.Square(entry.Move.Src)))
+			out.WriteByte(byte(checkers.Square(entry.Move.Dst)))
+			binary.Write(out, binary.LittleEndian, entry.Weight)
+		}
+	}
+	return out.Flush()
+}
+
+func Read(r io.Reader) (*Book, error) {
+	in := bufio.NewReader(r)
+	header := make([]byte, len(MAGIC)+1)
+	if _, err := io.ReadFull(in, header); err != nil {
+		return nil, err
+	}
+	if string(header[:len(MAGIC)]) != MAGIC {
+		return nil, errors.New("not an opening book")
+	}
+	if header[len(MAGIC)] != VERSION {
+		return nil, errors.New(fmt.Sprintf("unsupported book version: %v", header[len(MAGIC)]))
+	}
+	var count uint32
+	if err := binary.Read(in, binary.LittleEndian, &count); err != nil {
+		return nil, err
+	}
+	book := New()
+	for i := uint32(0); i < count; i++ {
+		var record struct {
+			Black, Red, Kings uint32
+			Turn, Jumper      uint8
+			Entries           uint8
+		}
+		if err := binary.Read(in, binary.LittleEndian, &record); err != nil {
+			return nil, err
+		}
+		turn := checkers.BLACK_PLAYER
+		if record.Turn == redTurn {
+			turn = checkers.RED_PLAYER
+		}
+		jumper := checkers.SquarePos(int(record.Jumper))
+		key, err := checkers.NewSnapshot(record.Black, record.Red, record.Kings, turn, jumper)
+		if err != nil {
+			return nil, err
+		}
+		entries := make([]Entry, record.Entries)
+		for j := range entries {
+			var entry struct {
+				Src, Dst uint8
+				Weight   uint32
+			}
+			if err := binary.Read(in, binary.LittleEndian, &entry); err != nil {
+				return nil, err
+			}
+			move := checkers.Move{Src: checkers.SquarePos(int(entry.Src)), Dst: checkers.SquarePos(int(entry.Dst))}
+			if move.Src == checkers.NO_POS || move.Dst == checkers.NO_POS {
+				return nil, errors.New(fmt.Sprintf("invalid move in book: %v-%v", entry.Src, entry.Dst))
+			}
+			entries[j] = Entry{move, entry.Weight}
+		}
+		book.Positions[key] = entries
+	}
+	return book, nil
+}
@@ -0,0 +1,297 @@
+// Package bot runs an ai.Engine as a long-running player on a checkers server.
+// A bot plays several games at once, each on its own connection, and resumes
+// its seat when a connection drops.
+package bot
+
+import (
+	"context"
+	"errors"
+	"github.com/batkinson/checkers-go/checkers"
+	"github.com/batkinson/checkers-go/checkers/ai"
+	"github.com/batkinson/checkers-go/checkers/client"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// RETRY_DELAY is how long a bot waits before redialling or retrying a
+// command that failed. A seat is resumed up to RESUME_ATTEMPTS times, since
+// the server may not yet have noticed the old connection drop.
+const (
+	RETRY_DELAY     = time.Second
+	RESUME_ATTEMPTS = 5
+)
+
+// Bot plays Games games at a time on the server at Addr. It joins games
+// waiting for a player, other than its own, and otherwise creates one with
+// NewArgs and waits for an opponent. Once a game is won it starts another.
+type Bot struct {
+	Addr    string
+	Engine  ai.Engine
+	Games   int
+	NewArgs []string
+	Retry   time.Duration
+	Logger  *log.Logger
+	Dial    func(addr string) (*client.Client, error)
+	// engine serializes ChooseMove, so engines need not be safe for
+	// concurrent use.
+	engine sync.Mutex
+	lock   sync.Mutex
+	own    map[string]bool
+}
+
+func New(addr string, engine ai.Engine) *Bot {
+	return &Bot{
+		Addr:   addr,
+		Engine: engine,
+		Games:  1,
+		Retry:  RETRY_DELAY,
+		Logger: log.New(os.Stdout, "", 0),
+		Dial:   client.Dial,
+		own:    map[string]bool{},
+	}
+}
+
+// Run plays until ctx is done, then quits every game and returns ctx.Err().
+func (bot *Bot) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for i := 1; i <= bot.Games; i++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			(&seat{bot: bot, n: n}).run(ctx)
+		}(i)
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+func (bot *Bot) chooseMove(game *checkers.Game, player checkers.Player) checkers.Move {
+	bot.engine.Lock()
+	defer bot.engine.Unlock()
+	return bot.Engine.ChooseMove(game, player)
+}
+
+func (bot *Bot) setOwn(gameId string, own bool) {
+	bot.lock.Lock()
+	defer bot.lock.Unlock()
+	if own {
+		bot.own[gameId] = true
+	} else {
+		delete(bot.own, gameId)
+	}
+}
+
+func (bot *Bot) isOwn(gameId string) bool {
+	bot.lock.Lock()
+	defer bot.lock.Unlock()
+	return bot.own[gameId]
+}
+
+// wait sleeps for the retry delay, reporting false if ctx ended first.
+func (bot *Bot) wait(ctx context.Context) bool {
+	timer := time.NewTimer(bot.Retry)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// seat is one of a bot's games and the connection it is played on. gameId is
+// the game the events being handled belong to.
+type seat struct {
+	bot    *Bot
+	n      int
+	client *client.Client
+	gameId string
+}
+
+func (seat *seat) log(v ...interface{}) {
+	seat.bot.Logger.Println(append([]interface{}{"seat", seat.n}, v...)...)
+}
+
+func (seat *seat) run(ctx context.Context) {
+	token := ""
+	for seat.connect(ctx, token) {
+		seat.play(ctx)
+		if ctx.Err() != nil {
+			seat.client.Quit()
+			seat.client.Close()
+			return
+		}
+		seat.log("disconnected", seat.client.Err())
+		seat.client.Close()
+		token = seat.client.Session()
+	}
+}
+
+// connect dials until it succeeds, reclaiming the seat of a game under way
+// with its session token.
+func (seat *seat) connect(ctx context.Context, token string) bool {
+	for ctx.Err() == nil {
+		c, err := seat.bot.Dial(seat.bot.Addr)
+		if err != nil {
+			seat.log("dialing", err)
+			if !seat.bot.wait(ctx) {
+				return false
+			}
+			continue
+		}
+		seat.client, seat.gameId = c, ""
+		if token != "" {
+			seat.resume(ctx, token)
+		}
+		return true
+	}
+	return false
+}
+
+func (seat *seat) resume(ctx context.Context, token string) {
+	for attempt := 1; ; attempt++ {
+		err := seat.client.Resume(token)
+		if err == nil {
+			seat.log("resumed", seat.client.GameId())
+			return
+		}
+		if attempt == RESUME_ATTEMPTS || seat.client.Err() != nil || !seat.bot.wait(ctx) {
+			seat.log("resuming", err)
+			return
+		}
+	}
+}
+
+// enter joins a game waiting for a player, or creates one.
+func (seat *seat) enter() error {
+	waiting, err := seat.client.List()
+	if err != nil {
+		return err
+	}
+	for _, gameId := range waiting {
+		if seat.bot.isOwn(gameId) {
+			continue
+		}
+		if err := seat.client.Join(gameId); err == nil {
+			seat.log("joined", gameId)
+			return nil
+		}
+	}
+	gameId, err := seat.client.New(seat.bot.NewArgs...)
+	if err != nil {
+		return err
+	}
+	seat.bot.setOwn(gameId, true)
+	seat.log("created", gameId)
+	return nil
+}
+
+// seek moves a seat waiting in its own game into another game waiting for a
+// player, so bots that created games at the same time still meet. Only games
+// with lower ids are joined, so two waiting seats never swap games. The seat
+// leaves its game first, as a JOIN refused after the server has left the
+// current game would leave the seat in none, and ignores events still queued
+// from it. If the JOIN fails, play enters another game.
+func (seat *seat) seek() {
+	if seat.gameId == "" || !seat.bot.isOwn(seat.gameId) || seat.client.Turn() != checkers.NO_PLAYER {
+		return
+	}
+	waiting, err := seat.client.List()
+	if err != nil {
+		return
+	}
+	for _, gameId := range waiting {
+		if gameId >= seat.gameId || seat.bot.isOwn(gameId) {
+			continue
+		}
+		seat.bot.setOwn(seat.gameId, false)
+		seat.gameId = ""
+		if err := seat.client.Leave(); err != nil {
+			seat.log("leaving", err)
+		}
+		if err := seat.client.Join(gameId); err == nil {
+			seat.log("joined", gameId)
+		}
+		return
+	}
+}
+
+// play handles the connection's events until it ends or ctx is done. While
+// waiting for an opponent it looks for other waiting games every Retry.
+func (seat *seat) play(ctx context.Context) {
+	ticker := time.NewTicker(seat.bot.Retry)
+	defer ticker.Stop()
+	for {
+		if seat.client.GameId() == "" {
+			if err := seat.enter(); err != nil {
+				if seat.client.Err() != nil {
+					return
+				}
+				seat.log("entering", err)
+				if !seat.bot.wait(ctx) {
+					return
+				}
+				continue
+			}
+		}
+		select {
+		case event, ok := <-seat.client.Events:
+			if !ok {
+				return
+			}
+			seat.handle(event)
+		case <-ticker.C:
+			seat.seek()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (seat *seat) handle(event client.Event) {
+	switch event.Type {
+	case client.GAME_ID:
+		seat.gameId = event.GameId
+	case client.TURN:
+		if seat.gameId != "" && event.Player == seat.client.Player() && seat.client.Winner() == checkers.NO_PLAYER {
+			seat.move()
+		}
+	case client.WINNER:
+		if seat.gameId == "" {
+			return
+		}
+		seat.log("finished", seat.gameId, "winner", event.Player.Color)
+		seat.bot.setOwn(seat.gameId, false)
+		seat.gameId = ""
+		if err := seat.client.Leave(); err != nil {
+			seat.log("leaving", err)
+		}
+	}
+}
+
+// move plays the engine's move. If the server refuses it, as when the local
+// board lost track of a multi-jump across a reconnection, the other legal
+// moves are tried in turn so the game does not stall.
+func (seat *seat) move() {
+	player, game := seat.client.Player(), seat.client.Game()
+	if game == nil || !game.TurnIs(player) {
+		return
+	}
+	move := seat.bot.chooseMove(game, player)
+	if move == checkers.NO_MOVE {
+		return
+	}
+	err := seat.client.Move(move)
+	var reply *client.Error
+	if err == nil || !errors.As(err, &reply) {
+		return
+	}
+	seat.log("refused", move, err)
+	for _, other := range game.LegalMoves() {
+		if other != move && seat.client.Move(other) == nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"github.com/batkinson/checkers-go/checkers"
+	"github.com/batkinson/checkers-go/checkers/ai"
+	"github.com/batkinson/checkers-go/checkers/client"
+	"github.com/batkinson/checkers-go/checkers/server"
+	"io/ioutil"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const TEST_TIMEOUT = 5 * time.Second
+
+// WIN_POSITION leaves black one capture and red a reply that takes black's
+// last piece, so games between bots end within two moves.
+const WIN_POSITION = "********|********|********|********|*b******|**r*****|********|****r***"
+
+// transcript is a Logger's output, safe to read while bots write to it.
+type transcript struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (t *transcript) Write(p []byte) (int, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.buf.Write(p)
+}
+
+func (t *transcript) count(s string) int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return strings.Count(t.buf.String(), s)
+}
+
+// listen returns the address of a server started for the test.
+func listen(t *testing.T) string {
+	srv := server.New(server.DefaultConfig())
+	srv.Logger = log.New(ioutil.Discard, "", 0)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(listener)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), TEST_TIMEOUT)
+		defer cancel()
+		srv.Shutdown(ctx)
+	})
+	return listener.Addr().String()
+}
+
+// start runs a bot until the test ends.
+func start(t *testing.T, bot *Bot) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error)
+	go func() {
+		stopped <- bot.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		select {
+		case err := <-stopped:
+			if err != context.Canceled {
+				t.Errorf("expected %v, got %v", context.Canceled, err)
+			}
+		case <-time.After(TEST_TIMEOUT):
+			t.Errorf("timed out stopping bot")
+		}
+	})
+}
+
+func eventually(t *testing.T, condition func() bool, what string) {
+	t.Helper()
+	deadline := time.Now().Add(TEST_TIMEOUT)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %v", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestBotsPlayEachOther(t *testing.T) {
+	addr := listen(t)
+	logs := &transcript{}
+	for seed := int64(1); seed <= 2; seed++ {
+		bot := New(addr, ai.NewRandom(seed))
+		bot.Games = 2
+		bot.NewArgs = []string{"POSITION", WIN_POSITION}
+		bot.Retry = 10 * time.Millisecond
+		bot.Logger = log.New(logs, "", 0)
+		start(t, bot)
+	}
+	eventually(t, func() bool { return logs.count("finished") >= 8 }, "games to finish")
+	if refused := logs.count("refused"); refused != 0 {
+		t.Errorf("expected no refused moves, got %v\n%v", refused, logs.buf.String())
+	}
+}
+
+func TestBotResumes(t *testing.T) {
+	addr := listen(t)
+	human, err := client.Dial(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer human.Close()
+	if _, err := human.New(); err != nil {
+		t.Fatal(err)
+	}
+	dialed := make(chan *client.Client, 2)
+	logs := &transcript{}
+	bot := New(addr, ai.NewRandom(1))
+	bot.Retry = 10 * time.Millisecond
+	bot.Logger = log.New(logs, "", 0)
+	bot.Dial = func(addr string) (*client.Client, error) {
+		c, err := client.Dial(addr)
+		if err == nil {
+			dialed <- c
+		}
+		return c, err
+	}
+	start(t, bot)
+	first := <-dialed
+	eventually(t, func() bool { return human.Turn() == human.Player() }, "the human's turn")
+	first.Close()
+	awaitResumed(t, human)
+	<-dialed
+	move := human.Game().LegalMoves()[0]
+	if err := human.Move(move); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, func() bool { return human.Turn() == human.Player() && human.LastMove() != move }, "the bot's reply")
+	if logs.count("resuming") != 0 {
+		t.Errorf("expected the seat to be resumed, got %v", logs.count("resuming"))
+	}
+	if human.Winner() != checkers.NO_PLAYER {
+		t.Errorf("expected the game to continue, got winner %v", human.Winner())
+	}
+}
+
+// awaitResumed waits for the client to be told its opponent is back.
+func awaitResumed(t *testing.T, c *client.Client) {
+	t.Helper()
+	timeout := time.After(TEST_TIMEOUT)
+	for {
+		select {
+		case event := <-c.Events:
+			if event.Type == client.RESUMED {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for the bot to resume")
+		}
+	}
+}
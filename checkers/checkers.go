@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -112,20 +113,37 @@ func init() {
 			}
 		}
 	}
+
+	Ballots = generateBallots()
 }
 
+// Game holds the board and whose turn it is. Jumper is the position of a piece
+// part way through a multi-jump, which must keep jumping; it is NO_POS (or
+// any other unplayable position) otherwise.
 type Game struct {
-	Pieces map[Pos]Piece
-	Turn   Player
+	Pieces       map[Pos]Piece
+	Turn         Player
+	Jumper       Pos
+	listeners    []listener
+	nextListener int
 }
 
 func New() *Game {
 	pieces := make(map[Pos]Piece)
-	game := &Game{pieces, BLACK_PLAYER}
+	game := &Game{Pieces: pieces, Turn: BLACK_PLAYER, Jumper: NO_POS}
 	game.addInitialPieces()
 	return game
 }
 
+// Clone returns a copy of the game that shares no state with the original.
+func (game *Game) Clone() *Game {
+	pieces := make(map[Pos]Piece, len(game.Pieces))
+	for pos, piece := range game.Pieces {
+		pieces[pos] = piece
+	}
+	return &Game{Pieces: pieces, Turn: game.Turn, Jumper: game.Jumper}
+}
+
 func (game *Game) addInitialPieces() {
 	for pos := range Usable {
 		if pos.Y >= 0 && pos.Y < 3 {
@@ -165,10 +183,19 @@ func (game *Game) Winner() Player {
 	return NO_PLAYER
 }
 
+// Continuing reports whether a multi-jump is under way, in which case only
+// the jumping piece may move.
+func (game *Game) Continuing() bool {
+	return Usable[game.Jumper]
+}
+
 func (game *Game) ValidMove(src, dst Pos) bool {
 	if !game.PieceAt(src) || game.PieceAt(dst) {
 		return false
 	}
+	if game.Continuing() && src != game.Jumper {
+		return false
+	}
 	piece := game.Pieces[src]
 	if (!piece.King && Moves[piece.Player][src][dst]) || (piece.King && KingMoves[src][dst]) {
 		return !game.playerHasJump(piece.Player)
@@ -190,21 +217,27 @@ func (game *Game) ValidJump(src, dst Pos) bool {
 	}
 }
 
-func (game *Game) kingPiece(dst Pos) {
+func (game *Game) kingPiece(dst Pos) (crowned bool) {
 	if !game.PieceAt(dst) {
-		return
+		return false
 	}
 	piece := game.Pieces[dst]
-	if (dst.Y == 0 && piece.Player == RED_PLAYER) ||
-		(dst.Y == BOARD_DIM-1 && piece.Player == BLACK_PLAYER) {
+	if !piece.King && ((dst.Y == 0 && piece.Player == RED_PLAYER) ||
+		(dst.Y == BOARD_DIM-1 && piece.Player == BLACK_PLAYER)) {
 		piece.King = true
 		game.Pieces[dst] = piece
+		return true
 	}
+	return false
 }
 
 func (game *Game) updateTurn(dst Pos, jumped bool) {
 	opponent := Opponents[game.Turn]
-	if (!jumped || !game.jumpPossibleFrom(dst)) && game.playerHasMove(opponent) {
+	continuing := jumped && game.jumpPossibleFrom(dst)
+	game.Jumper = NO_POS
+	if continuing {
+		game.Jumper = dst
+	} else if game.playerHasMove(opponent) {
 		game.Turn = opponent
 	}
 }
@@ -271,32 +304,105 @@ func (game *Game) playerHasJump(player Player) bool {
 	return false
 }
 
+func (game *Game) movesFrom(src Pos) map[Pos]bool {
+	piece := game.Pieces[src]
+	if piece.King {
+		return KingMoves[src]
+	}
+	return Moves[piece.Player][src]
+}
+
+func (game *Game) jumpsFrom(src Pos) map[Pos]Pos {
+	piece := game.Pieces[src]
+	if piece.King {
+		return KingJumps[src]
+	}
+	return Jumps[piece.Player][src]
+}
+
+// LegalMoves returns every move the player whose turn it is may make, ordered
+// by source then destination square. Once the game has a winner there are no
+// legal moves.
+func (game *Game) LegalMoves() []Move {
+	jumps, moves := []Move{}, []Move{}
+	if game.Winner() != NO_PLAYER {
+		return moves
+	}
+	for src, piece := range game.Pieces {
+		if piece.Player != game.Turn || (game.Continuing() && src != game.Jumper) {
+			continue
+		}
+		for dst := range game.jumpsFrom(src) {
+			if game.ValidJump(src, dst) {
+				jumps = append(jumps, Move{src, dst})
+			}
+		}
+		for dst := range game.movesFrom(src) {
+			if !game.PieceAt(dst) {
+				moves = append(moves, Move{src, dst})
+			}
+		}
+	}
+	if len(jumps) > 0 {
+		moves = jumps
+	}
+	sort.Slice(moves, func(i, j int) bool {
+		si, sj := Square(moves[i].Src), Square(moves[j].Src)
+		if si != sj {
+			return si < sj
+		}
+		return Square(moves[i].Dst) < Square(moves[j].Dst)
+	})
+	return moves
+}
+
 func (game *Game) Move(src, dst Pos) (captured Pos, err error) {
 	captured = NO_POS
 	err = nil
 	if !game.PieceAt(src) {
-		return NO_POS, errors.New(fmt.Sprintf("No piece at source position: %v", src))
+		return NO_POS, &MoveError{src, dst, NO_PLAYER, ErrNoPiece}
 	}
+	player := game.Pieces[src].Player
 	if game.PieceAt(dst) {
-		return NO_POS, errors.New(fmt.Sprintf("Already piece at destination position: %v", dst))
+		return NO_POS, &MoveError{src, dst, player, ErrOccupied}
+	}
+	if !game.TurnIs(player) {
+		return NO_POS, &MoveError{src, dst, player, ErrWrongTurn}
 	}
-	if !game.TurnIs(game.Pieces[src].Player) {
-		return NO_POS, errors.New(fmt.Sprintf("Not %v's turn", game.Pieces[src].Player))
+	if game.Continuing() && (src != game.Jumper || !game.ValidJump(src, dst)) {
+		return NO_POS, &MoveError{src, dst, player, ErrContinuationRequired}
 	}
 	if !game.ValidMove(src, dst) {
-		return NO_POS, errors.New(fmt.Sprintf("Invalid move: %v to %v", src, dst))
+		if game.movesFrom(src)[dst] {
+			return NO_POS, &MoveError{src, dst, player, ErrCaptureRequired}
+		}
+		return NO_POS, &MoveError{src, dst, player, ErrIllegalDirection}
 	}
+	moved, turn := game.Pieces[src], game.Turn
+	events := []Event{{Type: PIECE_MOVED, Src: src, Dst: dst, Pos: NO_POS, Piece: moved, Player: turn}}
 	if game.ValidJump(src, dst) {
 		game.Pieces[dst] = game.Pieces[src]
 		delete(game.Pieces, src)
 		captured = Capture(src, dst)
+		events = append(events, Event{PIECE_CAPTURED, src, dst, captured, game.Pieces[captured], turn})
 		delete(game.Pieces, captured)
 	} else {
 		game.Pieces[dst] = game.Pieces[src]
 		delete(game.Pieces, src)
 	}
 	game.updateTurn(dst, captured != NO_POS)
-	game.kingPiece(dst)
+	if game.kingPiece(dst) {
+		events = append(events, Event{PIECE_CROWNED, src, dst, dst, moved, turn})
+	}
+	if game.Turn != turn {
+		events = append(events, Event{TURN_CHANGED, src, dst, NO_POS, moved, game.Turn})
+	}
+	if winner := game.Winner(); captured != NO_POS && winner != NO_PLAYER {
+		events = append(events, Event{GAME_FINISHED, src, dst, NO_POS, moved, winner})
+	}
+	for _, event := range events {
+		game.notify(event)
+	}
 	return
 }
 
@@ -333,8 +439,11 @@ func Parse(s string) (*Game, error) {
 		return nil, errors.New(fmt.Sprintf("invalid board string: %v", s))
 	}
 	pieces := make(map[Pos]Piece)
-	result := &Game{pieces, BLACK_PLAYER}
+	result := &Game{Pieces: pieces, Turn: BLACK_PLAYER, Jumper: NO_POS}
 	for y, row := range strings.Split(s, ROW_SEP) {
+		if len(row) != BOARD_DIM {
+			return nil, errors.New(fmt.Sprintf("invalid board, row %v has %v squares", y, len(row)))
+		}
 		for x, c := range strings.Split(row, "") {
 			if x >= BOARD_DIM || y >= BOARD_DIM {
 				return nil, errors.New(fmt.Sprintf("invalid board, piece out of bounds: %v, %v", x, y))
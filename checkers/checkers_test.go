@@ -177,7 +177,7 @@ func TestInvalidJump(t *testing.T) {
 		t.Errorf("expected jump to fail with error")
 	}
 	if game.PieceAt(dst) || !game.PieceAt(src) || !game.PieceAt(capLoc) {
-		t.Errorf("expected jump to have no effect", src, dst, capLoc)
+		t.Errorf("expected jump from %v to %v capturing %v to have no effect", src, dst, capLoc)
 	}
 }
 
@@ -431,3 +431,31 @@ func TestParse(t *testing.T) {
 		t.Errorf("parsed game not equal to game: expected %v, got %v", expected, actual)
 	}
 }
+
+func TestClone(t *testing.T) {
+	game := New()
+	game.Turn = RED_PLAYER
+	clone := game.Clone()
+	if clone.String() != game.String() || clone.Turn != game.Turn {
+		t.Errorf("expected clone to match original")
+	}
+	clone.Move(Pos{0, 5}, Pos{1, 4})
+	if game.PieceAt(Pos{1, 4}) || !game.PieceAt(Pos{0, 5}) || game.Turn != RED_PLAYER {
+		t.Errorf("expected moves on the clone to leave the original unchanged")
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"*b*b*b*b|b*b*b*b*|*b*b*b*b|********|********|r*r*r*r*|*r*r*r*r|r*r*r*r",
+		"*b*b*b*b|b*b*b*b*|*b*b*b*b|********|********|r*r*r*r*|*r*r*r*r|r*r*r*r*x",
+		"*b*b*b*b|b*b*b*b*|*b*b*b*b|********|********|r*r*r*r*|*r*r*r*r|r*r*r*x*",
+		"BBBBBBBB|BBBBBBBB|BBBBBBBB|BBBBBBBB|BBBBBBBB|BBBBBBBB|BBBBBBBB|BBBBBBB|",
+		"*b*b*b*b|b*b*b*b**|*b*b*b*b|*******|********|r*r*r*r*|*r*r*r*r|r*r*r*r*",
+	} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("expected %q to be rejected", s)
+		}
+	}
+}
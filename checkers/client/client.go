@@ -0,0 +1,403 @@
+// Package client speaks the checkers server's line protocol, keeping a local
+// mirror of the game the connection is in.
+package client
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/batkinson/checkers-go/checkers"
+	"net"
+	"strings"
+	"sync"
+)
+
+var ErrClosed = errors.New("connection closed")
+
+// Client is one connection to a checkers server. Commands may be called from
+// any goroutine; they are sent one at a time and return once the server has
+// answered OK or ERROR. Every STATUS line the server sends is applied to the
+// client's state and then delivered on Events, in order. Events is closed once
+// the connection ends, and queues without limit, so a client that is not
+// interested in events may ignore it.
+type Client struct {
+	Events   <-chan Event
+	conn     net.Conn
+	commands sync.Mutex
+	replies  chan error
+	done     chan bool
+	stop     chan bool
+	stopOnce sync.Once
+	// lock guards everything below, which the reader updates.
+	lock     sync.Mutex
+	queued   *sync.Cond
+	queue    []Event
+	ended    bool
+	err      error
+	pending  int
+	gameId   string
+	player   checkers.Player
+	session  string
+	game     *checkers.Game
+	turn     checkers.Player
+	lastMove checkers.Move
+	winner   checkers.Player
+	games    []string
+}
+
+// Dial connects to a server at addr, such as localhost:5000.
+func Dial(addr string) (*Client, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(conn), nil
+}
+
+// NewClient speaks the protocol over an established connection.
+func NewClient(conn net.Conn) *Client {
+	events := make(chan Event)
+	client := &Client{
+		Events:  events,
+		conn:    conn,
+		replies: make(chan error, 1),
+		done:    make(chan bool),
+		stop:    make(chan bool),
+	}
+	client.queued = sync.NewCond(&client.lock)
+	client.reset()
+	go client.read()
+	go client.deliver(events)
+	return client
+}
+
+// reset forgets the game the client was in.
+func (client *Client) reset() {
+	client.gameId, client.session, client.game = "", "", nil
+	client.player, client.turn, client.winner = checkers.NO_PLAYER, checkers.NO_PLAYER, checkers.NO_PLAYER
+	client.lastMove = checkers.NO_MOVE
+}
+
+func (client *Client) read() {
+	lines := bufio.NewScanner(client.conn)
+	for lines.Scan() {
+		line := strings.TrimRight(lines.Text(), "\r")
+		switch {
+		case line == "OK":
+			client.reply(nil)
+		case strings.HasPrefix(line, "ERROR"):
+			if err, parseErr := ParseError(line); parseErr == nil {
+				client.reply(err)
+			}
+		default:
+			if event, err := ParseStatus(line); err == nil {
+				client.apply(event)
+			}
+		}
+	}
+	client.lock.Lock()
+	if client.err == nil {
+		client.err = lines.Err()
+	}
+	if client.err == nil {
+		client.err = ErrClosed
+	}
+	client.ended = true
+	client.queued.Broadcast()
+	client.lock.Unlock()
+	close(client.done)
+}
+
+// reply answers the command waiting for it. An ERROR nobody is waiting for,
+// such as SERVER_FULL or SLOW_CLIENT, is why the connection is about to end.
+func (client *Client) reply(err error) {
+	client.lock.Lock()
+	if client.pending == 0 {
+		if err != nil {
+			client.err = err
+		}
+		client.lock.Unlock()
+		return
+	}
+	client.pending--
+	client.lock.Unlock()
+	client.replies <- err
+}
+
+// apply updates the client's state from an event and queues it for delivery.
+func (client *Client) apply(event Event) {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	switch event.Type {
+	case GAME_ID:
+		client.reset()
+		client.gameId = event.GameId
+	case BOARD:
+		board := event.Board.Clone()
+		if client.game != nil {
+			board.Turn, board.Jumper = client.game.Turn, client.game.Jumper
+		}
+		client.game = board
+	case YOU_ARE:
+		client.player = event.Player
+	case SESSION:
+		client.session = event.Token
+	case TURN:
+		client.turn = event.Player
+		if client.game != nil && event.Player != checkers.NO_PLAYER {
+			client.game.Turn = event.Player
+		}
+	case MOVED:
+		client.lastMove = event.Move
+		client.mirrorMove(event.Move)
+	case CAPTURED:
+		if client.game != nil {
+			delete(client.game.Pieces, event.Pos)
+		}
+	case KING:
+		if client.game != nil && client.game.PieceAt(event.Pos) {
+			piece := client.game.Pieces[event.Pos]
+			piece.King = true
+			client.game.Pieces[event.Pos] = piece
+		}
+	case WINNER:
+		client.winner = event.Player
+	case LIST:
+		client.games = event.Games
+	}
+	client.queue = append(client.queue, event)
+	client.queued.Signal()
+}
+
+// mirrorMove plays a move on the mirror, so it tracks multi-jumps as the
+// server does. A move the mirror disagrees with is applied as given; the
+// CAPTURED, KING and TURN lines that follow correct the rest.
+func (client *Client) mirrorMove(move checkers.Move) {
+	if client.game == nil {
+		return
+	}
+	if _, err := client.game.Move(move.Src, move.Dst); err == nil {
+		return
+	}
+	if piece, ok := client.game.Pieces[move.Src]; ok {
+		delete(client.game.Pieces, move.Src)
+		client.game.Pieces[move.Dst] = piece
+	}
+	client.game.Jumper = checkers.NO_POS
+}
+
+func (client *Client) deliver(events chan<- Event) {
+	defer close(events)
+	client.lock.Lock()
+	for {
+		for len(client.queue) == 0 && !client.ended {
+			client.queued.Wait()
+		}
+		if len(client.queue) == 0 {
+			client.lock.Unlock()
+			return
+		}
+		event := client.queue[0]
+		client.queue = client.queue[1:]
+		client.lock.Unlock()
+		select {
+		case events <- event:
+		case <-client.stop:
+			return
+		}
+		client.lock.Lock()
+	}
+}
+
+// Command sends a command and waits for the server's answer, returning an
+// *Error if it was ERROR.
+func (client *Client) Command(cmd string, args ...string) error {
+	client.commands.Lock()
+	defer client.commands.Unlock()
+	client.lock.Lock()
+	if client.ended {
+		client.lock.Unlock()
+		return client.Err()
+	}
+	client.pending++
+	client.lock.Unlock()
+	line := strings.Join(append([]string{cmd}, args...), " ")
+	if _, err := fmt.Fprintf(client.conn, "%v\r\n", line); err != nil {
+		// No reply is coming, so the next one must not be taken for it.
+		client.lock.Lock()
+		if client.pending > 0 {
+			client.pending--
+		}
+		client.lock.Unlock()
+		return err
+	}
+	select {
+	case err := <-client.replies:
+		return err
+	case <-client.done:
+		select {
+		case err := <-client.replies:
+			return err
+		default:
+			return client.Err()
+		}
+	}
+}
+
+// Close ends the connection without leaving any game, as if it had dropped.
+func (client *Client) Close() error {
+	client.stopOnce.Do(func() {
+		close(client.stop)
+	})
+	return client.conn.Close()
+}
+
+// Done is closed once the connection has ended.
+func (client *Client) Done() <-chan bool {
+	return client.done
+}
+
+// Err reports why the connection ended: an ERROR the server sent before
+// closing it, a read error or ErrClosed. It is nil while connected.
+func (client *Client) Err() error {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	if !client.ended {
+		return nil
+	}
+	return client.err
+}
+
+// New creates a game and joins it, returning its id. Arguments are passed on,
+// so New("BALLOT", "12") and New("POSITION", fen) start from other positions.
+func (client *Client) New(args ...string) (string, error) {
+	if err := client.Command("NEW", args...); err != nil {
+		return "", err
+	}
+	return client.GameId(), nil
+}
+
+func (client *Client) list(args ...string) ([]string, error) {
+	if err := client.Command("LIST", args...); err != nil {
+		return nil, err
+	}
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	return client.games, nil
+}
+
+// List returns the ids of games waiting for a player.
+func (client *Client) List() ([]string, error) {
+	return client.list()
+}
+
+// ListSpectate returns the ids of games that can be watched.
+func (client *Client) ListSpectate() ([]string, error) {
+	return client.list("SPECTATE")
+}
+
+func (client *Client) Join(gameId string) error {
+	return client.Command("JOIN", gameId)
+}
+
+func (client *Client) Spectate(gameId string) error {
+	return client.Command("SPECTATE", gameId)
+}
+
+// Resume reclaims a seat held after a dropped connection, using the token
+// from that connection's Session.
+func (client *Client) Resume(token string) error {
+	return client.Command("RESUME", token)
+}
+
+// Leave leaves the client's game. The client forgets its game when the
+// server says it is in none, as happens once a watched game is removed.
+func (client *Client) Leave() error {
+	err := client.Command("LEAVE")
+	var reply *Error
+	if err == nil || (errors.As(err, &reply) && reply.Code == "NOT_IN_GAME") {
+		client.lock.Lock()
+		client.reset()
+		client.lock.Unlock()
+	}
+	return err
+}
+
+func (client *Client) Move(move checkers.Move) error {
+	return client.Command("MOVE", fmt.Sprint(move.Src.X), fmt.Sprint(move.Src.Y), fmt.Sprint(move.Dst.X), fmt.Sprint(move.Dst.Y))
+}
+
+// Refresh asks for the board and turn, resynchronising the mirror. Only
+// players may ask.
+func (client *Client) Refresh() error {
+	if err := client.Command("BOARD"); err != nil {
+		return err
+	}
+	return client.Command("TURN")
+}
+
+// Quit leaves any game and waits for the server to close the connection.
+func (client *Client) Quit() error {
+	if err := client.Command("QUIT"); err != nil {
+		return err
+	}
+	<-client.done
+	client.lock.Lock()
+	client.reset()
+	client.lock.Unlock()
+	return nil
+}
+
+// GameId is the game the client is in, or "" if none.
+func (client *Client) GameId() string {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	return client.gameId
+}
+
+// Player is the colour the client plays, or NO_PLAYER when spectating or not
+// in a game.
+func (client *Client) Player() checkers.Player {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	return client.player
+}
+
+// Session is the token for resuming the client's seat.
+func (client *Client) Session() string {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	return client.session
+}
+
+// Game returns a copy of the mirror of the client's game, or nil if it is not
+// in one.
+func (client *Client) Game() *checkers.Game {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	if client.game == nil {
+		return nil
+	}
+	return client.game.Clone()
+}
+
+// Turn is the player to move, or NO_PLAYER while the game waits for one.
+func (client *Client) Turn() checkers.Player {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	return client.turn
+}
+
+func (client *Client) LastMove() checkers.Move {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	return client.lastMove
+}
+
+// Winner is the winner of the client's game, or NO_PLAYER while it is under
+// way.
+func (client *Client) Winner() checkers.Player {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	return client.winner
+}
@@ -0,0 +1,196 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"github.com/batkinson/checkers-go/checkers"
+	"github.com/batkinson/checkers-go/checkers/server"
+	"io/ioutil"
+	"log"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const TEST_TIMEOUT = 2 * time.Second
+
+// WIN_POSITION leaves black one capture and red a reply that takes black's
+// last piece.
+const WIN_POSITION = "********|********|********|********|*b******|**r*****|********|****r***"
+
+// connect returns clients of a server started for the test.
+func connect(t *testing.T, count int) []*Client {
+	srv := server.New(server.DefaultConfig())
+	srv.Logger = log.New(ioutil.Discard, "", 0)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(listener)
+	clients := make([]*Client, count)
+	for i := range clients {
+		if clients[i], err = Dial(listener.Addr().String()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() {
+		for _, client := range clients {
+			client.Close()
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), TEST_TIMEOUT)
+		defer cancel()
+		srv.Shutdown(ctx)
+	})
+	return clients
+}
+
+// await returns the next event of the given type.
+func await(t *testing.T, client *Client, eventType EventType) Event {
+	t.Helper()
+	timeout := time.After(TEST_TIMEOUT)
+	for {
+		select {
+		case event, ok := <-client.Events:
+			if !ok {
+				t.Fatalf("connection closed waiting for %v", eventType)
+			}
+			if event.Type == eventType {
+				return event
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for %v", eventType)
+		}
+	}
+}
+
+// seat starts a game between a and b and returns them as black and red.
+func seat(t *testing.T, a, b *Client, args ...string) (black, red *Client) {
+	id, err := a.New(args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Join(id); err != nil {
+		t.Fatal(err)
+	}
+	if a.Player() == checkers.BLACK_PLAYER {
+		return a, b
+	}
+	return b, a
+}
+
+func TestClientPlay(t *testing.T) {
+	clients := connect(t, 3)
+	black, red := seat(t, clients[0], clients[1])
+	watcher := clients[2]
+	if games, err := watcher.ListSpectate(); err != nil || len(games) != 1 || games[0] != black.GameId() {
+		t.Fatalf("expected to list game %v, got %v, %v", black.GameId(), games, err)
+	}
+	if err := watcher.Spectate(black.GameId()); err != nil {
+		t.Fatal(err)
+	}
+	game := checkers.New()
+	for ply := 0; ply < 12; ply++ {
+		move := game.LegalMoves()[0]
+		mover := map[checkers.Player]*Client{checkers.BLACK_PLAYER: black, checkers.RED_PLAYER: red}[game.Turn]
+		if err := mover.Move(move); err != nil {
+			t.Fatalf("expected %v to be accepted: %v", move, err)
+		}
+		game.Move(move.Src, move.Dst)
+	}
+	await(t, watcher, MOVED)
+	if err := black.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	for _, client := range []*Client{black, red, watcher} {
+		if mirror := client.Game(); mirror.String() != game.String() || mirror.Turn != game.Turn {
+			t.Errorf("expected mirror %v to move %v, got %v to move %v", game, game.Turn.Color, mirror, mirror.Turn.Color)
+		}
+	}
+	if watcher.Player() != checkers.NO_PLAYER || black.Player() != checkers.BLACK_PLAYER {
+		t.Errorf("expected spectator and black, got %v and %v", watcher.Player(), black.Player())
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	clients := connect(t, 2)
+	black, red := seat(t, clients[0], clients[1])
+	err := red.Move(checkers.Move{Src: checkers.Pos{X: 0, Y: 5}, Dst: checkers.Pos{X: 1, Y: 4}})
+	var reply *Error
+	if !errors.As(err, &reply) || reply.Code != "NOT_YOUR_TURN" {
+		t.Errorf("expected NOT_YOUR_TURN, got %v", err)
+	}
+	if err := black.Join("nonsense"); err == nil {
+		t.Errorf("expected joining a missing game to fail")
+	}
+	if err := black.Quit(); err != nil {
+		t.Errorf("expected quit to succeed: %v", err)
+	}
+	if _, ok := <-black.Done(); ok || black.Err() != ErrClosed {
+		t.Errorf("expected connection to be closed, got %v", black.Err())
+	}
+	if err := black.Command("LIST"); err != ErrClosed {
+		t.Errorf("expected %v, got %v", ErrClosed, err)
+	}
+	await(t, red, LEFT)
+}
+
+func TestClientWinner(t *testing.T) {
+	clients := connect(t, 2)
+	black, red := seat(t, clients[0], clients[1], "POSITION", WIN_POSITION)
+	if err := black.Move(checkers.Move{Src: checkers.Pos{X: 1, Y: 4}, Dst: checkers.Pos{X: 3, Y: 6}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := red.Move(checkers.Move{Src: checkers.Pos{X: 4, Y: 7}, Dst: checkers.Pos{X: 2, Y: 5}}); err != nil {
+		t.Fatal(err)
+	}
+	if event := await(t, black, WINNER); event.Player != checkers.RED_PLAYER {
+		t.Errorf("expected red to win, got %v", event.Player)
+	}
+	if black.Winner() != checkers.RED_PLAYER || red.Winner() != checkers.RED_PLAYER {
+		t.Errorf("expected both to know red won, got %v and %v", black.Winner(), red.Winner())
+	}
+	if last := red.LastMove(); last.String() != "31x22" {
+		t.Errorf("expected last move 31x22, got %v", last)
+	}
+	if black.Game().Winner() != checkers.RED_PLAYER {
+		t.Errorf("expected mirror to show red won, got %v", black.Game())
+	}
+}
+
+// failingConn fails writes while failing is set.
+type failingConn struct {
+	net.Conn
+	failing int32
+}
+
+func (conn *failingConn) Write(p []byte) (int, error) {
+	if atomic.LoadInt32(&conn.failing) != 0 {
+		return 0, errors.New("write failed")
+	}
+	return conn.Conn.Write(p)
+}
+
+func TestClientFailedWrite(t *testing.T) {
+	local, remote := net.Pipe()
+	defer remote.Close()
+	conn := &failingConn{Conn: local, failing: 1}
+	client := NewClient(conn)
+	defer client.Close()
+	if err := client.Command("LIST"); err == nil {
+		t.Fatal("expected the write to fail")
+	}
+	remote.Write([]byte("ERROR SLOW_CLIENT too slow\r\nSTATUS GAME_ID after\r\n"))
+	await(t, client, GAME_ID)
+	atomic.StoreInt32(&conn.failing, 0)
+	go func() {
+		lines := bufio.NewReader(remote)
+		if _, err := lines.ReadString('\n'); err == nil {
+			remote.Write([]byte("OK\r\n"))
+		}
+	}()
+	if err := client.Command("LIST"); err != nil {
+		t.Errorf("expected the next command's own reply, got %v", err)
+	}
+}
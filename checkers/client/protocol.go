@@ -0,0 +1,213 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"github.com/batkinson/checkers-go/checkers"
+	"strconv"
+	"strings"
+)
+
+// EventType is the kind of STATUS line an event was parsed from.
+type EventType int
+
+const (
+	GAME_ID EventType = iota
+	BALLOT
+	BOARD
+	YOU_ARE
+	SESSION
+	JOINED
+	LEFT
+	DISCONNECTED
+	RESUMED
+	TURN
+	MOVED
+	CAPTURED
+	KING
+	WINNER
+	LIST
+	PRETTY
+	UNKNOWN
+)
+
+var EventNames = map[EventType]string{
+	GAME_ID:      "GAME_ID",
+	BALLOT:       "BALLOT",
+	BOARD:        "BOARD",
+	YOU_ARE:      "YOU_ARE",
+	SESSION:      "SESSION",
+	JOINED:       "JOINED",
+	LEFT:         "LEFT",
+	DISCONNECTED: "DISCONNECTED",
+	RESUMED:      "RESUMED",
+	TURN:         "TURN",
+	MOVED:        "MOVED",
+	CAPTURED:     "CAPTURED",
+	KING:         "KING",
+	WINNER:       "WINNER",
+	LIST:         "LIST",
+	PRETTY:       "PRETTY",
+}
+
+func (t EventType) String() string {
+	if name, ok := EventNames[t]; ok {
+		return name
+	}
+	return "UNKNOWN"
+}
+
+// Event is one STATUS line from the server. Line is the line as received.
+// Player is the colour named by YOU_ARE, JOINED, LEFT, DISCONNECTED, RESUMED,
+// TURN and WINNER, and NO_PLAYER for a game still waiting for a player. Move
+// is set by MOVED, Pos by CAPTURED and KING, and Games by LIST, with Spectate
+// set for LIST SPECTATE. Other fields hold their zero value, NO_PLAYER, NO_POS
+// or NO_MOVE.
+type Event struct {
+	Type     EventType
+	Line     string
+	GameId   string
+	Ballot   checkers.Ballot
+	Board    *checkers.Game
+	Player   checkers.Player
+	Move     checkers.Move
+	Pos      checkers.Pos
+	Token    string
+	Games    []string
+	Spectate bool
+	Text     string
+}
+
+var ErrInvalidLine = errors.New("invalid line")
+
+// Error is a command's ERROR reply. Code is stable, Message is for people.
+type Error struct {
+	Code    string
+	Message string
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("%v %v", err.Code, err.Message)
+}
+
+// ParseError parses an ERROR line.
+func ParseError(line string) (*Error, error) {
+	parts := strings.SplitN(line, " ", 3)
+	if len(parts) < 2 || parts[0] != "ERROR" || parts[1] == "" {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidLine, line)
+	}
+	err := &Error{Code: parts[1]}
+	if len(parts) > 2 {
+		err.Message = parts[2]
+	}
+	return err, nil
+}
+
+func parsePlayer(color string) (checkers.Player, bool) {
+	player, ok := checkers.Players[color]
+	return player, ok
+}
+
+func parseCoords(fields []string) ([]int, bool) {
+	coords := make([]int, len(fields))
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, false
+		}
+		coords[i] = n
+	}
+	return coords, true
+}
+
+// ParseStatus parses a STATUS line. Well-formed lines of a kind it does not
+// know parse as UNKNOWN, so newer servers do not break older clients.
+func ParseStatus(line string) (Event, error) {
+	event := Event{Type: UNKNOWN, Line: line, Ballot: checkers.NO_BALLOT, Player: checkers.NO_PLAYER, Move: checkers.NO_MOVE, Pos: checkers.NO_POS}
+	invalid := fmt.Errorf("%w: %v", ErrInvalidLine, line)
+	fields := strings.Fields(line)
+	if len(fields) < 2 || fields[0] != "STATUS" {
+		return event, invalid
+	}
+	args := fields[2:]
+	ok := true
+	switch fields[1] {
+	case "GAME_ID":
+		event.Type = GAME_ID
+		ok = len(args) == 1
+		if ok {
+			event.GameId = args[0]
+		}
+	case "BALLOT":
+		event.Type = BALLOT
+		ok = len(args) > 1
+		if ok {
+			ballot, err := checkers.FindBallot(args[0])
+			event.Ballot, ok = ballot, err == nil
+		}
+	case "BOARD":
+		event.Type = BOARD
+		ok = len(args) == 1
+		if ok {
+			board, err := checkers.Parse(args[0])
+			event.Board, ok = board, err == nil
+		}
+	case "YOU_ARE", "JOINED", "LEFT", "DISCONNECTED", "RESUMED", "WINNER":
+		event.Type = map[string]EventType{
+			"YOU_ARE":      YOU_ARE,
+			"JOINED":       JOINED,
+			"LEFT":         LEFT,
+			"DISCONNECTED": DISCONNECTED,
+			"RESUMED":      RESUMED,
+			"WINNER":       WINNER,
+		}[fields[1]]
+		ok = len(args) == 1
+		if ok {
+			event.Player, ok = parsePlayer(args[0])
+		}
+	case "TURN":
+		event.Type = TURN
+		ok = len(args) == 1
+		if ok && args[0] != "waiting" {
+			event.Player, ok = parsePlayer(args[0])
+		}
+	case "SESSION":
+		event.Type = SESSION
+		ok = len(args) == 1
+		if ok {
+			event.Token = args[0]
+		}
+	case "MOVED":
+		event.Type = MOVED
+		coords, parsed := parseCoords(args)
+		ok = parsed && len(coords) == 4
+		if ok {
+			event.Move = checkers.Move{Src: checkers.Pos{X: coords[0], Y: coords[1]}, Dst: checkers.Pos{X: coords[2], Y: coords[3]}}
+		}
+	case "CAPTURED", "KING":
+		event.Type = CAPTURED
+		if fields[1] == "KING" {
+			event.Type = KING
+		}
+		coords, parsed := parseCoords(args)
+		ok = parsed && len(coords) == 2
+		if ok {
+			event.Pos = checkers.Pos{X: coords[0], Y: coords[1]}
+		}
+	case "LIST":
+		event.Type = LIST
+		if len(args) > 0 && args[0] == "SPECTATE" {
+			event.Spectate, args = true, args[1:]
+		}
+		event.Games = append([]string{}, args...)
+	case "PRETTY":
+		event.Type = PRETTY
+		if parts := strings.SplitN(line, " ", 3); len(parts) == 3 {
+			event.Text = parts[2]
+		}
+	}
+	if !ok {
+		return event, invalid
+	}
+	return event, nil
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"errors"
+	"github.com/batkinson/checkers-go/checkers"
+	"reflect"
+	"testing"
+)
+
+func TestParseStatus(t *testing.T) {
+	ballot, _ := checkers.FindBallot("12")
+	start := checkers.New()
+	for _, test := range []struct {
+		line     string
+		expected Event
+	}{
+		{"STATUS GAME_ID abc", Event{Type: GAME_ID, GameId: "abc"}},
+		{"STATUS BALLOT 12 " + ballot.String(), Event{Type: BALLOT, Ballot: ballot}},
+		{"STATUS BOARD " + start.String(), Event{Type: BOARD, Board: start}},
+		{"STATUS YOU_ARE red", Event{Type: YOU_ARE, Player: checkers.RED_PLAYER}},
+		{"STATUS SESSION 0a1b", Event{Type: SESSION, Token: "0a1b"}},
+		{"STATUS JOINED black", Event{Type: JOINED, Player: checkers.BLACK_PLAYER}},
+		{"STATUS DISCONNECTED red", Event{Type: DISCONNECTED, Player: checkers.RED_PLAYER}},
+		{"STATUS TURN waiting", Event{Type: TURN}},
+		{"STATUS TURN black", Event{Type: TURN, Player: checkers.BLACK_PLAYER}},
+		{"STATUS MOVED 1 2 2 3", Event{Type: MOVED, Move: checkers.Move{Src: checkers.Pos{X: 1, Y: 2}, Dst: checkers.Pos{X: 2, Y: 3}}}},
+		{"STATUS CAPTURED 2 5", Event{Type: CAPTURED, Pos: checkers.Pos{X: 2, Y: 5}}},
+		{"STATUS KING 3 7", Event{Type: KING, Pos: checkers.Pos{X: 3, Y: 7}}},
+		{"STATUS WINNER red", Event{Type: WINNER, Player: checkers.RED_PLAYER}},
+		{"STATUS LIST ", Event{Type: LIST, Games: []string{}}},
+		{"STATUS LIST SPECTATE a b", Event{Type: LIST, Games: []string{"a", "b"}, Spectate: true}},
+		{"STATUS PRETTY   a b c", Event{Type: PRETTY, Text: "  a b c"}},
+		{"STATUS SHRUG", Event{Type: UNKNOWN}},
+	} {
+		expected := test.expected
+		expected.Line = test.line
+		if expected.Player == (checkers.Player{}) {
+			expected.Player = checkers.NO_PLAYER
+		}
+		if expected.Move == (checkers.Move{}) {
+			expected.Move = checkers.NO_MOVE
+		}
+		if expected.Pos == (checkers.Pos{}) && expected.Type != CAPTURED {
+			expected.Pos = checkers.NO_POS
+		}
+		event, err := ParseStatus(test.line)
+		if err != nil {
+			t.Errorf("expected %q to parse: %v", test.line, err)
+		} else if !reflect.DeepEqual(event, expected) {
+			t.Errorf("expected %q to parse as %+v, got %+v", test.line, expected, event)
+		}
+	}
+}
+
+func TestParseStatusInvalid(t *testing.T) {
+	for _, line := range []string{
+		"",
+		"OK",
+		"STATUS",
+		"STATUS GAME_ID",
+		"STATUS BOARD nonsense",
+		"STATUS YOU_ARE green",
+		"STATUS MOVED 1 2 3",
+		"STATUS CAPTURED x y",
+		"STATUS BALLOT 0 9-13",
+	} {
+		if _, err := ParseStatus(line); !errors.Is(err, ErrInvalidLine) {
+			t.Errorf("expected %q to be invalid, got %v", line, err)
+		}
+	}
+}
+
+func TestParseError(t *testing.T) {
+	err, parseErr := ParseError("ERROR NOT_YOUR_TURN not your turn")
+	if parseErr != nil || *err != (Error{"NOT_YOUR_TURN", "not your turn"}) {
+		t.Errorf("expected NOT_YOUR_TURN, got %v, %v", err, parseErr)
+	}
+	if _, parseErr := ParseError("ERROR"); !errors.Is(parseErr, ErrInvalidLine) {
+		t.Errorf("expected bare ERROR to be invalid, got %v", parseErr)
+	}
+}
@@ -0,0 +1,41 @@
+package checkers
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Reasons a move can be rejected. Errors returned by Game.Move wrap one of
+// these and can be tested with errors.Is.
+var (
+	ErrNoPiece              = errors.New("no piece at source position")
+	ErrOccupied             = errors.New("already piece at destination position")
+	ErrWrongTurn            = errors.New("not player's turn")
+	ErrIllegalDirection     = errors.New("invalid move")
+	ErrCaptureRequired      = errors.New("capture required")
+	ErrContinuationRequired = errors.New("jump continuation required")
+)
+
+// MoveError describes a rejected move. Reason is one of the Err* values above.
+type MoveError struct {
+	Src    Pos
+	Dst    Pos
+	Player Player
+	Reason error
+}
+
+func (err *MoveError) Error() string {
+	switch err.Reason {
+	case ErrNoPiece:
+		return fmt.Sprintf("%v: %v", err.Reason, err.Src)
+	case ErrOccupied:
+		return fmt.Sprintf("%v: %v", err.Reason, err.Dst)
+	case ErrWrongTurn:
+		return fmt.Sprintf("not %v's turn", err.Player.Color)
+	}
+	return fmt.Sprintf("%v: %v to %v", err.Reason, err.Src, err.Dst)
+}
+
+func (err *MoveError) Unwrap() error {
+	return err.Reason
+}
@@ -0,0 +1,68 @@
+package checkers
+
+import (
+	"errors"
+	"testing"
+)
+
+func expectReason(t *testing.T, game *Game, src, dst Pos, reason error) {
+	t.Helper()
+	before := game.String()
+	_, err := game.Move(src, dst)
+	if !errors.Is(err, reason) {
+		t.Errorf("expected move %v to %v to fail with %q, got %v", src, dst, reason, err)
+	}
+	var moveErr *MoveError
+	if !errors.As(err, &moveErr) || moveErr.Src != src || moveErr.Dst != dst {
+		t.Errorf("expected a MoveError for %v to %v, got %#v", src, dst, err)
+	}
+	if game.String() != before {
+		t.Errorf("expected failed move %v to %v to leave the board unchanged", src, dst)
+	}
+}
+
+func TestMoveErrors(t *testing.T) {
+	game := New()
+	expectReason(t, game, Pos{2, 3}, Pos{3, 4}, ErrNoPiece)
+	expectReason(t, game, Pos{2, 1}, Pos{3, 2}, ErrOccupied)
+	expectReason(t, game, Pos{0, 5}, Pos{1, 4}, ErrWrongTurn)
+	expectReason(t, game, Pos{3, 2}, Pos{3, 4}, ErrIllegalDirection)
+	game.Pieces[Pos{4, 3}] = Piece{RED_PLAYER, false}
+	expectReason(t, game, Pos{1, 2}, Pos{0, 3}, ErrCaptureRequired)
+	expectReason(t, game, Pos{3, 2}, Pos{1, 4}, ErrIllegalDirection)
+}
+
+func TestMoveErrorMessages(t *testing.T) {
+	game := New()
+	_, err := game.Move(Pos{0, 5}, Pos{1, 4})
+	if err == nil || err.Error() != "not red's turn" {
+		t.Errorf("expected turn error message, got %v", err)
+	}
+	_, err = game.Move(Pos{2, 3}, Pos{3, 4})
+	if err == nil || err.Error() != "no piece at source position: {2 3}" {
+		t.Errorf("expected missing piece error message, got %v", err)
+	}
+}
+
+func TestContinuationRequired(t *testing.T) {
+	game := New()
+	game.Pieces[Pos{2, 3}] = Piece{RED_PLAYER, false}
+	delete(game.Pieces, Pos{3, 6})
+	if _, err := game.Move(Pos{3, 2}, Pos{1, 4}); err != nil {
+		t.Fatalf("expected jump to succeed: %v", err)
+	}
+	if !game.Continuing() || game.Jumper != (Pos{1, 4}) {
+		t.Fatalf("expected jump to continue from %v, got %v", Pos{1, 4}, game.Jumper)
+	}
+	expectReason(t, game, Pos{5, 2}, Pos{4, 3}, ErrContinuationRequired)
+	moves := game.LegalMoves()
+	if len(moves) != 1 || moves[0] != (Move{Pos{1, 4}, Pos{3, 6}}) {
+		t.Errorf("expected only the continuation to be legal, got %v", moves)
+	}
+	if _, err := game.Move(Pos{1, 4}, Pos{3, 6}); err != nil {
+		t.Fatalf("expected continuation to succeed: %v", err)
+	}
+	if game.Continuing() || !game.TurnIs(RED_PLAYER) {
+		t.Errorf("expected multi-jump to end with red to move")
+	}
+}
@@ -0,0 +1,69 @@
+package checkers
+
+type EventType int
+
+const (
+	PIECE_MOVED EventType = iota
+	PIECE_CAPTURED
+	PIECE_CROWNED
+	TURN_CHANGED
+	GAME_FINISHED
+)
+
+var EventNames = map[EventType]string{
+	PIECE_MOVED:    "moved",
+	PIECE_CAPTURED: "captured",
+	PIECE_CROWNED:  "crowned",
+	TURN_CHANGED:   "turn",
+	GAME_FINISHED:  "finished",
+}
+
+func (t EventType) String() string {
+	return EventNames[t]
+}
+
+// Event describes one change made by Game.Move. Src and Dst are always the
+// move that caused it. Pos is the square captured or crowned, and Piece is the
+// piece moved, captured or crowned as it was before the event. Player is the
+// mover, except for TURN_CHANGED where it is the player now to move and for
+// GAME_FINISHED where it is the winner.
+type Event struct {
+	Type   EventType
+	Src    Pos
+	Dst    Pos
+	Pos    Pos
+	Piece  Piece
+	Player Player
+}
+
+type Listener func(event Event)
+
+type listener struct {
+	id     int
+	notify Listener
+}
+
+// AddListener registers fn to be called, in registration order, for every
+// event a successful Move produces. Moves emit PIECE_MOVED, then
+// PIECE_CAPTURED and PIECE_CROWNED where they apply, then TURN_CHANGED if the
+// turn passed and GAME_FINISHED if the move won the game. Listeners are not
+// copied by Clone. The returned function removes the listener.
+func (game *Game) AddListener(fn Listener) (remove func()) {
+	game.nextListener++
+	id := game.nextListener
+	game.listeners = append(game.listeners, listener{id, fn})
+	return func() {
+		for i, l := range game.listeners {
+			if l.id == id {
+				game.listeners = append(game.listeners[:i:i], game.listeners[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func (game *Game) notify(event Event) {
+	for _, l := range game.listeners {
+		l.notify(event)
+	}
+}
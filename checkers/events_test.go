@@ -0,0 +1,92 @@
+package checkers
+
+import (
+	"testing"
+)
+
+func record(game *Game) (*[]Event, func()) {
+	events := []Event{}
+	remove := game.AddListener(func(event Event) {
+		events = append(events, event)
+	})
+	return &events, remove
+}
+
+func expectEvents(t *testing.T, events []Event, types ...EventType) {
+	t.Helper()
+	if len(events) != len(types) {
+		t.Fatalf("expected events %v, got %v", types, events)
+	}
+	for i, event := range events {
+		if event.Type != types[i] {
+			t.Errorf("expected event %v to be %v, got %v", i, types[i], event.Type)
+		}
+	}
+}
+
+func TestMoveEvents(t *testing.T) {
+	game := New()
+	events, _ := record(game)
+	src, dst := Pos{3, 2}, Pos{4, 3}
+	game.Move(src, dst)
+	expectEvents(t, *events, PIECE_MOVED, TURN_CHANGED)
+	if (*events)[0].Src != src || (*events)[0].Dst != dst || (*events)[0].Piece != (Piece{BLACK_PLAYER, false}) {
+		t.Errorf("expected move event from %v to %v, got %v", src, dst, (*events)[0])
+	}
+	if (*events)[1].Player != RED_PLAYER {
+		t.Errorf("expected turn to pass to red, got %v", (*events)[1].Player)
+	}
+	*events = (*events)[:0]
+	game.Move(Pos{0, 0}, Pos{1, 1})
+	if len(*events) != 0 {
+		t.Errorf("expected no events for a failed move, got %v", *events)
+	}
+}
+
+func TestCaptureEvents(t *testing.T) {
+	game := New()
+	game.Pieces[Pos{2, 3}] = Piece{RED_PLAYER, false}
+	delete(game.Pieces, Pos{3, 6})
+	events, _ := record(game)
+	game.Move(Pos{3, 2}, Pos{1, 4})
+	expectEvents(t, *events, PIECE_MOVED, PIECE_CAPTURED)
+	if (*events)[1].Pos != (Pos{2, 3}) || (*events)[1].Piece.Player != RED_PLAYER {
+		t.Errorf("expected red piece captured at %v, got %v", Pos{2, 3}, (*events)[1])
+	}
+}
+
+func TestCrownAndFinishEvents(t *testing.T) {
+	game, _ := Parse("********|********|********|********|********|********|*r*b****|********")
+	events, remove := record(game)
+	game.Move(Pos{3, 6}, Pos{4, 7})
+	expectEvents(t, *events, PIECE_MOVED, PIECE_CROWNED, TURN_CHANGED)
+	if (*events)[1].Pos != (Pos{4, 7}) {
+		t.Errorf("expected crowning at %v, got %v", Pos{4, 7}, (*events)[1])
+	}
+	*events = (*events)[:0]
+	game.Turn = BLACK_PLAYER
+	game.Pieces[Pos{3, 6}] = Piece{BLACK_PLAYER, true}
+	delete(game.Pieces, Pos{4, 7})
+	game.Pieces[Pos{2, 5}] = Piece{RED_PLAYER, false}
+	delete(game.Pieces, Pos{1, 6})
+	game.Move(Pos{3, 6}, Pos{1, 4})
+	expectEvents(t, *events, PIECE_MOVED, PIECE_CAPTURED, GAME_FINISHED)
+	if (*events)[2].Player != BLACK_PLAYER {
+		t.Errorf("expected black to win, got %v", (*events)[2].Player)
+	}
+	remove()
+	*events = (*events)[:0]
+	game.Move(Pos{1, 4}, Pos{0, 5})
+	if len(*events) != 0 {
+		t.Errorf("expected removed listener not to be notified, got %v", *events)
+	}
+}
+
+func TestCloneHasNoListeners(t *testing.T) {
+	game := New()
+	events, _ := record(game)
+	game.Clone().Move(Pos{3, 2}, Pos{4, 3})
+	if len(*events) != 0 {
+		t.Errorf("expected moves on a clone not to notify the original's listeners")
+	}
+}
@@ -0,0 +1,156 @@
+package checkers
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func countPieces(game *Game) map[Player]int {
+	counts := map[Player]int{}
+	for _, piece := range game.Pieces {
+		counts[piece.Player]++
+	}
+	return counts
+}
+
+// checkMove makes a move and verifies the rule invariants that must hold
+// whether or not it succeeds.
+func checkMove(t *testing.T, game *Game, src, dst Pos) {
+	t.Helper()
+	before := game.Clone()
+	beforeCounts := countPieces(game)
+	legal := false
+	for _, move := range game.LegalMoves() {
+		legal = legal || move == Move{src, dst}
+	}
+	moved, hadPiece := game.Pieces[src]
+	captured, err := game.Move(src, dst)
+	if err != nil {
+		if legal {
+			t.Fatalf("legal move %v to %v rejected on %v: %v", src, dst, before, err)
+		}
+		if game.String() != before.String() || game.Turn != before.Turn || game.Jumper != before.Jumper {
+			t.Fatalf("failed move %v to %v changed the game %v", src, dst, before)
+		}
+		return
+	}
+	if !legal {
+		t.Fatalf("move %v to %v accepted but not listed as legal on %v", src, dst, before)
+	}
+	afterCounts := countPieces(game)
+	for _, player := range Players {
+		if afterCounts[player] > beforeCounts[player] {
+			t.Fatalf("%v pieces rose from %v to %v after %v to %v", player.Color, beforeCounts[player], afterCounts[player], src, dst)
+		}
+	}
+	opponent := Opponents[moved.Player]
+	if captured != NO_POS {
+		if before.Pieces[captured].Player != opponent || game.PieceAt(captured) {
+			t.Fatalf("capture at %v did not remove an opponent's piece on %v", captured, before)
+		}
+		if afterCounts[opponent] != beforeCounts[opponent]-1 || afterCounts[moved.Player] != beforeCounts[moved.Player] {
+			t.Fatalf("capture changed piece counts from %v to %v", beforeCounts, afterCounts)
+		}
+	} else if afterCounts[opponent] != beforeCounts[opponent] {
+		t.Fatalf("non-capture changed %v's piece count", opponent.Color)
+	}
+	if !hadPiece || !game.PieceAt(dst) || game.PieceAt(src) || game.Pieces[dst].Player != moved.Player {
+		t.Fatalf("expected piece to move from %v to %v on %v", src, dst, before)
+	}
+	if moved.King && !game.Pieces[dst].King {
+		t.Fatalf("king lost its crown moving from %v to %v", src, dst)
+	}
+	for pos, piece := range before.Pieces {
+		if piece.King && pos != src && game.PieceAt(pos) && !game.Pieces[pos].King {
+			t.Fatalf("king at %v lost its crown", pos)
+		}
+	}
+	checkRoundTrip(t, game)
+}
+
+func checkRoundTrip(t *testing.T, game *Game) {
+	t.Helper()
+	parsed, err := Parse(game.String())
+	if err != nil || parsed.String() != game.String() {
+		t.Fatalf("board %v did not round-trip through Parse: %v", game, err)
+	}
+	if snapshot, err := game.Snapshot(); err != nil || snapshot.Game().String() != game.String() {
+		t.Fatalf("board %v did not round-trip through Snapshot: %v", game, err)
+	}
+}
+
+// playBytes drives a game from fuzz input. Each byte either picks one of the
+// legal moves or, with its high bit set, an arbitrary move built from it and
+// the following byte.
+func playBytes(t *testing.T, game *Game, data []byte) {
+	for i := 0; i < len(data); i++ {
+		b := data[i]
+		if b&0x80 != 0 && i+1 < len(data) {
+			i++
+			src := Pos{int(b>>3) & 7, int(b) & 7}
+			dst := Pos{int(data[i]>>3) & 7, int(data[i]) & 7}
+			checkMove(t, game, src, dst)
+			continue
+		}
+		moves := game.LegalMoves()
+		if len(moves) == 0 {
+			return
+		}
+		move := moves[int(b)%len(moves)]
+		checkMove(t, game, move.Src, move.Dst)
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	f.Add(New().String())
+	f.Add("*B*b*b*b|b*b*b*b*|*b*b*b*b|********|********|r*r*r*r*|*r*r*r*R|r*r*r*r*")
+	f.Add("********|********|********|********|********|********|********|********")
+	f.Fuzz(func(t *testing.T, s string) {
+		game, err := Parse(s)
+		if err != nil {
+			return
+		}
+		if game.String() != s {
+			t.Fatalf("parsed %q but printed %q", s, game.String())
+		}
+	})
+}
+
+func FuzzMoves(f *testing.F) {
+	f.Add(New().String(), false, []byte{0, 1, 2, 3, 4, 5, 6, 7})
+	f.Add(New().String(), true, []byte{0x9a, 0xa3, 3, 3, 3})
+	f.Add("********|********|********|********|****B***|***r*r**|********|********", false, []byte{0, 0, 0, 0})
+	f.Fuzz(func(t *testing.T, board string, redFirst bool, data []byte) {
+		game, err := Parse(board)
+		if err != nil {
+			game = New()
+		}
+		for pos := range game.Pieces {
+			if !Usable[pos] {
+				delete(game.Pieces, pos)
+			}
+		}
+		if redFirst {
+			game.Turn = RED_PLAYER
+		}
+		playBytes(t, game, data)
+	})
+}
+
+func TestRandomGameInvariants(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		game := New()
+		for ply := 0; ply < 300; ply++ {
+			moves := game.LegalMoves()
+			if len(moves) == 0 {
+				break
+			}
+			if rng.Intn(4) == 0 {
+				checkMove(t, game, Pos{rng.Intn(BOARD_DIM), rng.Intn(BOARD_DIM)}, Pos{rng.Intn(BOARD_DIM), rng.Intn(BOARD_DIM)})
+			}
+			move := moves[rng.Intn(len(moves))]
+			checkMove(t, game, move.Src, move.Dst)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package checkers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	SQUARES   = BOARD_DIM * BOARD_DIM / 2
+	MOVE_SEP  = "-"
+	JUMP_SEP  = "x"
+	NO_SQUARE = 0
+)
+
+// Move is a single step of a piece, either a simple move or one jump of a
+// (possibly multi-jump) capture.
+type Move struct {
+	Src Pos
+	Dst Pos
+}
+
+var NO_MOVE = Move{NO_POS, NO_POS}
+
+// IsJump reports whether the move jumps over a square.
+func (move Move) IsJump() bool {
+	dx := move.Dst.X - move.Src.X
+	return dx == 2 || dx == -2
+}
+
+// String renders the move in standard square-number notation, such as 9-13
+// or 22x15.
+func (move Move) String() string {
+	sep := MOVE_SEP
+	if move.IsJump() {
+		sep = JUMP_SEP
+	}
+	return fmt.Sprintf("%v%v%v", Square(move.Src), sep, Square(move.Dst))
+}
+
+// Square returns the standard checkers square number (1-32) of pos, with
+// squares 1-4 on black's back rank, or NO_SQUARE if pos is not playable.
+func Square(pos Pos) int {
+	if !Usable[pos] {
+		return NO_SQUARE
+	}
+	return pos.Y*(BOARD_DIM/2) + pos.X/2 + 1
+}
+
+// SquarePos is the inverse of Square, returning NO_POS for invalid squares.
+func SquarePos(square int) Pos {
+	if square < 1 || square > SQUARES {
+		return NO_POS
+	}
+	y := (square - 1) / (BOARD_DIM / 2)
+	x := ((square-1)%(BOARD_DIM/2))*2 + (y+1)%2
+	return Pos{x, y}
+}
+
+// ParseMove parses a single step in square-number notation such as 9-13 or
+// 22x15. Multi-jump sequences must be split into individual steps.
+func ParseMove(s string) (Move, error) {
+	sep := MOVE_SEP
+	if strings.Contains(s, JUMP_SEP) {
+		sep = JUMP_SEP
+	}
+	squares := strings.Split(s, sep)
+	if len(squares) != 2 {
+		return NO_MOVE, errors.New(fmt.Sprintf("invalid move: %v", s))
+	}
+	move := NO_MOVE
+	for i, square := range squares {
+		n, err := strconv.Atoi(square)
+		if err != nil || SquarePos(n) == NO_POS {
+			return NO_MOVE, errors.New(fmt.Sprintf("invalid square in move: %v", s))
+		}
+		if i == 0 {
+			move.Src = SquarePos(n)
+		} else {
+			move.Dst = SquarePos(n)
+		}
+	}
+	if move.IsJump() != (sep == JUMP_SEP) {
+		return NO_MOVE, errors.New(fmt.Sprintf("invalid move: %v", s))
+	}
+	return move, nil
+}
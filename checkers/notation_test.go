@@ -0,0 +1,60 @@
+package checkers
+
+import (
+	"testing"
+)
+
+func TestSquare(t *testing.T) {
+	cases := map[int]Pos{1: {1, 0}, 4: {7, 0}, 5: {0, 1}, 12: {7, 2}, 21: {0, 5}, 29: {0, 7}, 32: {6, 7}}
+	for square, pos := range cases {
+		if Square(pos) != square {
+			t.Errorf("expected %v to be square %v, got %v", pos, square, Square(pos))
+		}
+		if SquarePos(square) != pos {
+			t.Errorf("expected square %v to be %v, got %v", square, pos, SquarePos(square))
+		}
+	}
+	for pos := range Usable {
+		if SquarePos(Square(pos)) != pos {
+			t.Errorf("expected %v to round-trip through square numbers", pos)
+		}
+	}
+	if Square(Pos{0, 0}) != NO_SQUARE || SquarePos(33) != NO_POS || SquarePos(0) != NO_POS {
+		t.Errorf("expected unusable squares to be rejected")
+	}
+}
+
+func TestParseMove(t *testing.T) {
+	for _, text := range []string{"9-13", "22x15", "11x18"} {
+		move, err := ParseMove(text)
+		if err != nil {
+			t.Errorf("expected %v to parse: %v", text, err)
+		} else if move.String() != text {
+			t.Errorf("expected %v, got %v", text, move)
+		}
+	}
+	for _, text := range []string{"9", "9-13-17", "0-4", "9x13", "9-18", "a-b"} {
+		if _, err := ParseMove(text); err == nil {
+			t.Errorf("expected %v to fail to parse", text)
+		}
+	}
+}
+
+func TestLegalMoves(t *testing.T) {
+	game := New()
+	moves := game.LegalMoves()
+	expected := []string{"9-13", "9-14", "10-14", "10-15", "11-15", "11-16", "12-16"}
+	if len(moves) != len(expected) {
+		t.Fatalf("expected %v opening moves, got %v", len(expected), moves)
+	}
+	for i, move := range moves {
+		if move.String() != expected[i] {
+			t.Errorf("expected move %v to be %v, got %v", i, expected[i], move)
+		}
+	}
+	game.Pieces[SquarePos(14)] = Piece{RED_PLAYER, false}
+	moves = game.LegalMoves()
+	if len(moves) != 2 || !moves[0].IsJump() || !moves[1].IsJump() {
+		t.Errorf("expected only jumps when a capture is available, got %v", moves)
+	}
+}
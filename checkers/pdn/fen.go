@@ -0,0 +1,104 @@
+package pdn
+
+import (
+	"errors"
+	"fmt"
+	"github.com/batkinson/checkers-go/checkers"
+	"strconv"
+	"strings"
+)
+
+// FENColors maps the colour letters used in FEN setups to players. PDN calls
+// the second player white; R is accepted as well.
+var FENColors = map[string]checkers.Player{
+	"B": checkers.BLACK_PLAYER,
+	"W": checkers.RED_PLAYER,
+	"R": checkers.RED_PLAYER,
+}
+
+var fenLetters = map[checkers.Player]string{
+	checkers.BLACK_PLAYER: "B",
+	checkers.RED_PLAYER:   "W",
+}
+
+// ParseFEN reads a position in PDN FEN form, such as B:W21,22,K30:B1-3,K9,
+// giving the side to move followed by each side's pieces. Kings are prefixed
+// with K and runs of squares may be written as ranges.
+func ParseFEN(s string) (*checkers.Game, error) {
+	fields := strings.Split(strings.TrimSuffix(strings.TrimSpace(s), "."), ":")
+	if len(fields) < 1 || len(fields) > 3 {
+		return nil, errors.New(fmt.Sprintf("invalid FEN: %v", s))
+	}
+	turn, ok := FENColors[strings.ToUpper(fields[0])]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("invalid FEN, unknown turn: %v", fields[0]))
+	}
+	game := &checkers.Game{Pieces: map[checkers.Pos]checkers.Piece{}, Turn: turn, Jumper: checkers.NO_POS}
+	for _, field := range fields[1:] {
+		if field == "" {
+			return nil, errors.New(fmt.Sprintf("invalid FEN, empty side: %v", s))
+		}
+		player, ok := FENColors[strings.ToUpper(field[:1])]
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("invalid FEN, unknown side: %v", field))
+		}
+		if field = field[1:]; field == "" {
+			continue
+		}
+		for _, token := range strings.Split(field, ",") {
+			king := strings.HasPrefix(strings.ToUpper(token), "K")
+			if king {
+				token = token[1:]
+			}
+			first, last, err := parseSquares(token)
+			if err != nil {
+				return nil, err
+			}
+			for n := first; n <= last; n++ {
+				pos := checkers.SquarePos(n)
+				if _, taken := game.Pieces[pos]; taken {
+					return nil, errors.New(fmt.Sprintf("invalid FEN, square %v used twice", n))
+				}
+				game.Pieces[pos] = checkers.Piece{Player: player, King: king}
+			}
+		}
+	}
+	return game, nil
+}
+
+func parseSquares(token string) (first, last int, err error) {
+	bounds := strings.SplitN(token, "-", 2)
+	if first, err = strconv.Atoi(bounds[0]); err != nil {
+		return 0, 0, errors.New(fmt.Sprintf("invalid FEN square: %v", token))
+	}
+	last = first
+	if len(bounds) == 2 {
+		if last, err = strconv.Atoi(bounds[1]); err != nil {
+			return 0, 0, errors.New(fmt.Sprintf("invalid FEN square: %v", token))
+		}
+	}
+	if checkers.SquarePos(first) == checkers.NO_POS || checkers.SquarePos(last) == checkers.NO_POS || last < first {
+		return 0, 0, errors.New(fmt.Sprintf("invalid FEN square: %v", token))
+	}
+	return first, last, nil
+}
+
+// FEN writes the game's position in PDN FEN form, listing squares in order
+// with red as W.
+func FEN(game *checkers.Game) string {
+	fields := []string{fenLetters[game.Turn]}
+	for _, player := range []checkers.Player{checkers.RED_PLAYER, checkers.BLACK_PLAYER} {
+		squares := []string{}
+		for n := 1; n <= checkers.SQUARES; n++ {
+			if piece, ok := game.Pieces[checkers.SquarePos(n)]; ok && piece.Player == player {
+				square := strconv.Itoa(n)
+				if piece.King {
+					square = "K" + square
+				}
+				squares = append(squares, square)
+			}
+		}
+		fields = append(fields, fenLetters[player]+strings.Join(squares, ","))
+	}
+	return strings.Join(fields, ":")
+}
@@ -0,0 +1,57 @@
+package pdn
+
+import (
+	"github.com/batkinson/checkers-go/checkers"
+	"testing"
+)
+
+func TestParseFEN(t *testing.T) {
+	game, err := ParseFEN("B:W21-32:B1-12")
+	if err != nil {
+		t.Fatalf("expected start position to parse: %v", err)
+	}
+	if game.String() != checkers.New().String() || game.Turn != checkers.BLACK_PLAYER {
+		t.Errorf("expected start position, got %v", game)
+	}
+	game, err = ParseFEN("W:WK30,22:B9,K14.")
+	if err != nil {
+		t.Fatalf("expected endgame to parse: %v", err)
+	}
+	expected := map[int]checkers.Piece{
+		30: {Player: checkers.RED_PLAYER, King: true},
+		22: {Player: checkers.RED_PLAYER, King: false},
+		9:  {Player: checkers.BLACK_PLAYER, King: false},
+		14: {Player: checkers.BLACK_PLAYER, King: true},
+	}
+	if len(game.Pieces) != len(expected) || game.Turn != checkers.RED_PLAYER {
+		t.Fatalf("expected %v pieces with red to move, got %v", len(expected), game)
+	}
+	for square, piece := range expected {
+		if game.Pieces[checkers.SquarePos(square)] != piece {
+			t.Errorf("expected %v on %v, got %v", piece, square, game.Pieces[checkers.SquarePos(square)])
+		}
+	}
+}
+
+func TestParseFENInvalid(t *testing.T) {
+	for _, s := range []string{"", "X:W1:B2", "B:W33:B1", "B:W1:B1", "B:W5-2", "B:Q1", "B:W1,:B2", "B:W1:B2:W3"} {
+		if _, err := ParseFEN(s); err == nil {
+			t.Errorf("expected %q to be rejected", s)
+		}
+	}
+}
+
+func TestFEN(t *testing.T) {
+	if fen := FEN(checkers.New()); fen != "B:W21,22,23,24,25,26,27,28,29,30,31,32:B1,2,3,4,5,6,7,8,9,10,11,12" {
+		t.Errorf("unexpected start position FEN: %v", fen)
+	}
+	game, _ := ParseFEN("W:WK30,22:BK14,9")
+	if fen := FEN(game); fen != "W:W22,K30:B9,K14" {
+		t.Errorf("unexpected FEN: %v", fen)
+	}
+	parsed, _ := ParseFEN(FEN(game))
+	parsedKey, _ := parsed.Snapshot()
+	if key, err := game.Snapshot(); err != nil || parsedKey != key {
+		t.Errorf("expected FEN to round trip, got %v", parsed)
+	}
+}
@@ -0,0 +1,176 @@
+// Package pdn reads games in Portable Draughts Notation.
+package pdn
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/batkinson/checkers-go/checkers"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var Results = map[string]bool{
+	"1-0":     true,
+	"0-1":     true,
+	"1/2-1/2": true,
+	"2-0":     true,
+	"0-2":     true,
+	"1-1":     true,
+	"*":       true,
+}
+
+type Game struct {
+	Tags   map[string]string
+	Moves  []string
+	Result string
+}
+
+// Read parses every game in r. Comments, variations and move annotations are
+// discarded.
+func Read(r io.Reader) ([]*Game, error) {
+	games := []*Game{}
+	game := newGame()
+	scanner := bufio.NewScanner(r)
+	comment, variation := false, 0
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !comment && variation == 0 && strings.HasPrefix(line, "[") {
+			if len(game.Moves) > 0 || game.Result != "" {
+				games = append(games, game)
+				game = newGame()
+			}
+			name, value, err := parseTag(line)
+			if err != nil {
+				return nil, err
+			}
+			game.Tags[name] = value
+			continue
+		}
+		for _, token := range tokenize(line) {
+			switch {
+			case comment:
+				comment = token != "}"
+			case token == "{":
+				comment = true
+			case token == "(":
+				variation++
+			case token == ")":
+				variation--
+			case variation > 0:
+			case Results[token]:
+				game.Result = token
+				games = append(games, game)
+				game = newGame()
+			case isMoveNumber(token):
+			default:
+				if i := strings.LastIndex(token, "."); i >= 0 && isMoveNumber(token[:i+1]) {
+					token = token[i+1:]
+				}
+				game.Moves = append(game.Moves, strings.TrimRight(token, "!?"))
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(game.Moves) > 0 {
+		games = append(games, game)
+	}
+	return games, nil
+}
+
+func newGame() *Game {
+	return &Game{Tags: map[string]string{}, Moves: []string{}}
+}
+
+func parseTag(line string) (name, value string, err error) {
+	if !strings.HasSuffix(line, "]") {
+		return "", "", errors.New(fmt.Sprintf("invalid tag: %v", line))
+	}
+	fields := strings.SplitN(strings.Trim(line, "[]"), " ", 2)
+	if len(fields) != 2 {
+		return "", "", errors.New(fmt.Sprintf("invalid tag: %v", line))
+	}
+	return fields[0], strings.Trim(strings.TrimSpace(fields[1]), "\""), nil
+}
+
+func tokenize(line string) []string {
+	for _, delim := range []string{"{", "}", "(", ")"} {
+		line = strings.Replace(line, delim, " "+delim+" ", -1)
+	}
+	return strings.Fields(line)
+}
+
+func isMoveNumber(token string) bool {
+	trimmed := strings.TrimRight(token, ".")
+	if trimmed == token {
+		return false
+	}
+	_, err := strconv.Atoi(trimmed)
+	return err == nil
+}
+
+// Apply plays a move in PDN notation, such as 11-15, 22x15 or 15x22x29, and
+// returns the individual steps that were made. Jumps may omit intermediate
+// squares when the path is unambiguous.
+func Apply(game *checkers.Game, text string) ([]checkers.Move, error) {
+	if !strings.Contains(text, checkers.JUMP_SEP) {
+		move, err := checkers.ParseMove(text)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = game.Move(move.Src, move.Dst); err != nil {
+			return nil, err
+		}
+		return []checkers.Move{move}, nil
+	}
+	squares := strings.Split(text, checkers.JUMP_SEP)
+	path := make([]checkers.Pos, len(squares))
+	for i, square := range squares {
+		n, err := strconv.Atoi(square)
+		if err != nil || checkers.SquarePos(n) == checkers.NO_POS {
+			return nil, errors.New(fmt.Sprintf("invalid square in move: %v", text))
+		}
+		path[i] = checkers.SquarePos(n)
+	}
+	steps := []checkers.Move{}
+	for i := 1; i < len(path); i++ {
+		found := jumpPath(game, path[i-1], path[i])
+		if found == nil {
+			return nil, errors.New(fmt.Sprintf("illegal jump: %v", text))
+		}
+		for _, step := range found {
+			if _, err := game.Move(step.Src, step.Dst); err != nil {
+				return nil, err
+			}
+		}
+		steps = append(steps, found...)
+	}
+	return steps, nil
+}
+
+// jumpPath finds a sequence of legal jumps leading from src to dst.
+func jumpPath(game *checkers.Game, src, dst checkers.Pos) []checkers.Move {
+	if src == dst {
+		return []checkers.Move{}
+	}
+	for _, move := range game.LegalMoves() {
+		if move.Src != src || !move.IsJump() {
+			continue
+		}
+		next := game.Clone()
+		next.Move(move.Src, move.Dst)
+		if move.Dst == dst {
+			return []checkers.Move{move}
+		}
+		if !next.TurnIs(game.Turn) {
+			continue
+		}
+		if rest := jumpPath(next, move.Dst, dst); rest != nil {
+			return append([]checkers.Move{move}, rest...)
+		}
+	}
+	return nil
+}
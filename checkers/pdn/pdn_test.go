@@ -0,0 +1,71 @@
+package pdn
+
+import (
+	"github.com/batkinson/checkers-go/checkers"
+	"strings"
+	"testing"
+)
+
+const sample = `[Event "Casual"]
+[Black "Alice"]
+[White "Bob"]
+1. 11-15 23-19 {Old Faithful} 2. 8-11 22-17 (2... 9-13) 3. 9-13 17x10
+4. 7x14 1/2-1/2
+
+[Event "Second"]
+1.9-14 22-18 2.5-9? 18x9 3. 6x13 *
+`
+
+func TestRead(t *testing.T) {
+	games, err := Read(strings.NewReader(sample))
+	if err != nil {
+		t.Fatalf("expected successful read, got %v", err)
+	}
+	if len(games) != 2 {
+		t.Fatalf("expected 2 games, got %v", len(games))
+	}
+	if games[0].Tags["Black"] != "Alice" || games[0].Result != "1/2-1/2" {
+		t.Errorf("unexpected first game header: %v %v", games[0].Tags, games[0].Result)
+	}
+	expected := "11-15 23-19 8-11 22-17 9-13 17x10 7x14"
+	if strings.Join(games[0].Moves, " ") != expected {
+		t.Errorf("expected moves %v, got %v", expected, games[0].Moves)
+	}
+	expected = "9-14 22-18 5-9 18x9 6x13"
+	if strings.Join(games[1].Moves, " ") != expected || games[1].Tags["Event"] != "Second" {
+		t.Errorf("expected moves %v, got %v", expected, games[1].Moves)
+	}
+}
+
+func TestApply(t *testing.T) {
+	game := checkers.New()
+	for _, text := range []string{"11-15", "23-19", "8-11", "22-17", "9-13", "17-14", "10x17", "21x14"} {
+		if _, err := Apply(game, text); err != nil {
+			t.Fatalf("expected %v to apply: %v", text, err)
+		}
+	}
+	if game.PieceAt(checkers.SquarePos(17)) || !game.PieceAt(checkers.SquarePos(14)) {
+		t.Errorf("expected exchange to leave a red piece on 14, got %v", game)
+	}
+	if _, err := Apply(game, "24-20"); err == nil {
+		t.Errorf("expected move out of turn to fail")
+	}
+}
+
+func TestApplyMultiJump(t *testing.T) {
+	game, _ := checkers.Parse("********|********|********|********|********|********|********|********")
+	game.Pieces[checkers.SquarePos(1)] = checkers.Piece{Player: checkers.BLACK_PLAYER, King: false}
+	game.Pieces[checkers.SquarePos(6)] = checkers.Piece{Player: checkers.RED_PLAYER, King: false}
+	game.Pieces[checkers.SquarePos(15)] = checkers.Piece{Player: checkers.RED_PLAYER, King: false}
+	game.Pieces[checkers.SquarePos(32)] = checkers.Piece{Player: checkers.RED_PLAYER, King: false}
+	steps, err := Apply(game, "1x19")
+	if err != nil {
+		t.Fatalf("expected abbreviated multi-jump to apply: %v", err)
+	}
+	if len(steps) != 2 || steps[0].String() != "1x10" || steps[1].String() != "10x19" {
+		t.Errorf("expected 1x10 then 10x19, got %v", steps)
+	}
+	if game.PieceAt(checkers.SquarePos(6)) || game.PieceAt(checkers.SquarePos(15)) {
+		t.Errorf("expected both jumped pieces to be captured")
+	}
+}
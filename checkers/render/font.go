@@ -0,0 +1,58 @@
+package render
+
+import (
+	"image"
+	"image/color"
+)
+
+const (
+	GLYPH_WIDTH  = 3
+	GLYPH_HEIGHT = 5
+)
+
+// glyphs is a tiny bitmap font covering the characters used in board labels.
+var glyphs = map[rune][GLYPH_HEIGHT]string{
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"###", "..#", "###", "#..", "###"},
+	'3': {"###", "..#", "###", "..#", "###"},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "###", "..#", "###"},
+	'6': {"###", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", "..#", "..#", "..#"},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "###"},
+	'a': {".#.", "#.#", "###", "#.#", "#.#"},
+	'b': {"##.", "#.#", "##.", "#.#", "##."},
+	'c': {"###", "#..", "#..", "#..", "###"},
+	'd': {"##.", "#.#", "#.#", "#.#", "##."},
+	'e': {"###", "#..", "##.", "#..", "###"},
+	'f': {"###", "#..", "##.", "#..", "#.."},
+	'g': {"###", "#..", "#.#", "#.#", "###"},
+	'h': {"#.#", "#.#", "###", "#.#", "#.#"},
+}
+
+// textWidth returns the width in pixels of s drawn at scale.
+func textWidth(s string, scale int) int {
+	n := len([]rune(s))
+	if n == 0 {
+		return 0
+	}
+	return (n*(GLYPH_WIDTH+1) - 1) * scale
+}
+
+// drawText draws s with its top left corner at x, y, each font pixel being a
+// scale by scale block.
+func drawText(img *image.RGBA, x, y int, s string, scale int, c color.RGBA) {
+	for _, r := range s {
+		glyph := glyphs[r]
+		for row, line := range glyph {
+			for col, bit := range line {
+				if bit == '#' {
+					fillRect(img, image.Rect(x+col*scale, y+row*scale, x+(col+1)*scale, y+(row+1)*scale), c)
+				}
+			}
+		}
+		x += (GLYPH_WIDTH + 1) * scale
+	}
+}
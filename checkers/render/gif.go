@@ -0,0 +1,87 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"github.com/batkinson/checkers-go/checkers"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"io"
+	"time"
+)
+
+const (
+	DEFAULT_FRAME_DELAY = time.Second
+	DEFAULT_FINAL_DELAY = 3 * time.Second
+)
+
+// Palette holds every colour the renderer uses, so frames convert exactly.
+var Palette = color.Palette{
+	Background, LightSquare, DarkSquare, Highlight, BlackPiece,
+	RedPiece, Outline, Crown, Arrow, Label,
+}
+
+// GIFOptions control an animation. Board options apply to every frame, except
+// that LastMove is set to the move each frame shows. Start is the position
+// before the first move, the standard start when nil. A LoopCount of 0 loops
+// forever and -1 plays once.
+type GIFOptions struct {
+	Board      Options
+	Start      *checkers.Game
+	FrameDelay time.Duration
+	FinalDelay time.Duration
+	LoopCount  int
+}
+
+func DefaultGIFOptions() GIFOptions {
+	return GIFOptions{
+		Board:      DefaultOptions(),
+		FrameDelay: DEFAULT_FRAME_DELAY,
+		FinalDelay: DEFAULT_FINAL_DELAY,
+	}
+}
+
+// centiseconds converts a delay to GIF frame time units.
+func centiseconds(d time.Duration) int {
+	return int(d / (10 * time.Millisecond))
+}
+
+// Animate returns an animation with one frame for the starting position and
+// one after each move, each marking the move just made.
+func Animate(moves []checkers.Move, opts GIFOptions) (*gif.GIF, error) {
+	game := checkers.New()
+	if opts.Start != nil {
+		game = opts.Start.Clone()
+	}
+	board := opts.Board
+	board.LastMove = checkers.NO_MOVE
+	anim := &gif.GIF{LoopCount: opts.LoopCount}
+	addFrame := func() {
+		frame := Image(game, board)
+		paletted := image.NewPaletted(frame.Bounds(), Palette)
+		draw.Draw(paletted, frame.Bounds(), frame, image.Point{}, draw.Src)
+		anim.Image = append(anim.Image, paletted)
+		anim.Delay = append(anim.Delay, centiseconds(opts.FrameDelay))
+	}
+	addFrame()
+	for i, move := range moves {
+		if _, err := game.Move(move.Src, move.Dst); err != nil {
+			return nil, errors.New(fmt.Sprintf("move %v (%v): %v", i+1, move, err))
+		}
+		board.LastMove = move
+		addFrame()
+	}
+	anim.Delay[len(anim.Delay)-1] = centiseconds(opts.FinalDelay)
+	return anim, nil
+}
+
+// GIF writes an animated GIF of the game played by moves.
+func GIF(w io.Writer, moves []checkers.Move, opts GIFOptions) error {
+	anim, err := Animate(moves, opts)
+	if err != nil {
+		return err
+	}
+	return gif.EncodeAll(w, anim)
+}
@@ -0,0 +1,47 @@
+package render
+
+import (
+	"bytes"
+	"github.com/batkinson/checkers-go/checkers"
+	"image/gif"
+	"testing"
+	"time"
+)
+
+func TestGIF(t *testing.T) {
+	moves := []checkers.Move{}
+	for _, text := range []string{"11-15", "23-19", "8-11"} {
+		move, _ := checkers.ParseMove(text)
+		moves = append(moves, move)
+	}
+	opts := DefaultGIFOptions()
+	opts.Board.SquareSize = 16
+	opts.FrameDelay = 500 * time.Millisecond
+	opts.FinalDelay = 2 * time.Second
+	var buf bytes.Buffer
+	if err := GIF(&buf, moves, opts); err != nil {
+		t.Fatalf("expected gif to encode: %v", err)
+	}
+	anim, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatalf("expected gif to decode: %v", err)
+	}
+	if len(anim.Image) != len(moves)+1 {
+		t.Fatalf("expected %v frames, got %v", len(moves)+1, len(anim.Image))
+	}
+	if anim.Delay[0] != 50 || anim.Delay[len(moves)] != 200 {
+		t.Errorf("expected frame delays of 50 and final 200, got %v", anim.Delay)
+	}
+	src := moves[2].Src
+	x, y := src.X*16+1, src.Y*16+1
+	if anim.Image[0].At(x, y) == Highlight || anim.Image[3].At(x, y) != Highlight {
+		t.Errorf("expected only the final frame to highlight %v", moves[2])
+	}
+}
+
+func TestGIFIllegalMove(t *testing.T) {
+	move, _ := checkers.ParseMove("21-17")
+	if _, err := Animate([]checkers.Move{move}, DefaultGIFOptions()); err == nil {
+		t.Errorf("expected an illegal move to be reported")
+	}
+}
@@ -0,0 +1,127 @@
+package render
+
+import (
+	"fmt"
+	"github.com/batkinson/checkers-go/checkers"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+)
+
+// Image draws the board into a new RGBA image.
+func Image(game *checkers.Game, opts Options) *image.RGBA {
+	l := newLayout(opts)
+	size := l.size()
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	fillRect(img, img.Bounds(), Background)
+	numberScale := int(math.Max(1, float64(l.SquareSize)/24))
+	for y := 0; y < checkers.BOARD_DIM; y++ {
+		for x := 0; x < checkers.BOARD_DIM; x++ {
+			pos := checkers.Pos{X: x, Y: y}
+			ox, oy := l.origin(pos)
+			fill := LightSquare
+			if checkers.Usable[pos] {
+				fill = DarkSquare
+			}
+			if l.highlighted(pos) {
+				fill = Highlight
+			}
+			fillRect(img, image.Rect(ox, oy, ox+l.SquareSize, oy+l.SquareSize), fill)
+			if l.SquareNumbers && checkers.Usable[pos] {
+				drawText(img, ox+numberScale, oy+numberScale, fmt.Sprint(checkers.Square(pos)), numberScale, Background)
+			}
+		}
+	}
+	for _, pos := range piecePositions(game) {
+		piece := game.Pieces[pos]
+		cx, cy := l.center(pos)
+		r := l.pieceRadius()
+		fillCircle(img, cx, cy, r, Outline)
+		fillCircle(img, cx, cy, r-1, PieceColors[piece.Player])
+		if piece.King {
+			fillCircle(img, cx, cy, r/2+l.arrowWidth()/2, Crown)
+			fillCircle(img, cx, cy, r/2-l.arrowWidth()/2, PieceColors[piece.Player])
+		}
+	}
+	for _, move := range l.Arrows {
+		if !isMove(move) {
+			continue
+		}
+		shaft, head := l.arrowPoints(move)
+		drawLine(img, shaft[0][0], shaft[0][1], shaft[1][0], shaft[1][1], l.arrowWidth(), Arrow)
+		fillTriangle(img, head, Arrow)
+	}
+	if l.Coordinates {
+		scale := int(math.Max(1, float64(l.margin)/(GLYPH_HEIGHT*2)))
+		files, ranks := l.labelPositions()
+		for i := 0; i < checkers.BOARD_DIM; i++ {
+			file, rank := fileLabel(i), rankLabel(i)
+			drawText(img, files[i]-textWidth(file, scale)/2, size-(l.margin+GLYPH_HEIGHT*scale)/2, file, scale, Label)
+			drawText(img, (l.margin-textWidth(rank, scale))/2, ranks[i]-GLYPH_HEIGHT*scale/2, rank, scale, Label)
+		}
+	}
+	return img
+}
+
+// PNG writes the board as a PNG image.
+func PNG(w io.Writer, game *checkers.Game, opts Options) error {
+	return png.Encode(w, Image(game, opts))
+}
+
+func fillRect(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// fillShape sets every pixel within bounds whose centre is inside the shape.
+func fillShape(img *image.RGBA, bounds image.Rectangle, inside func(x, y float64) bool, c color.RGBA) {
+	bounds = bounds.Intersect(img.Bounds())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if inside(float64(x)+0.5, float64(y)+0.5) {
+				img.SetRGBA(x, y, c)
+			}
+		}
+	}
+}
+
+func box(minX, minY, maxX, maxY float64) image.Rectangle {
+	return image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX))+1, int(math.Ceil(maxY))+1)
+}
+
+func fillCircle(img *image.RGBA, cx, cy, r float64, c color.RGBA) {
+	fillShape(img, box(cx-r, cy-r, cx+r, cy+r), func(x, y float64) bool {
+		return math.Hypot(x-cx, y-cy) <= r
+	}, c)
+}
+
+func drawLine(img *image.RGBA, x1, y1, x2, y2, width float64, c color.RGBA) {
+	half := width / 2
+	dx, dy := x2-x1, y2-y1
+	lengthSq := dx*dx + dy*dy
+	fillShape(img, box(math.Min(x1, x2)-half, math.Min(y1, y2)-half, math.Max(x1, x2)+half, math.Max(y1, y2)+half), func(x, y float64) bool {
+		t := 0.0
+		if lengthSq > 0 {
+			t = math.Max(0, math.Min(1, ((x-x1)*dx+(y-y1)*dy)/lengthSq))
+		}
+		return math.Hypot(x-(x1+t*dx), y-(y1+t*dy)) <= half
+	}, c)
+}
+
+func fillTriangle(img *image.RGBA, p [3][2]float64, c color.RGBA) {
+	side := func(a, b [2]float64, x, y float64) float64 {
+		return (b[0]-a[0])*(y-a[1]) - (b[1]-a[1])*(x-a[0])
+	}
+	minX := math.Min(p[0][0], math.Min(p[1][0], p[2][0]))
+	minY := math.Min(p[0][1], math.Min(p[1][1], p[2][1]))
+	maxX := math.Max(p[0][0], math.Max(p[1][0], p[2][0]))
+	maxY := math.Max(p[0][1], math.Max(p[1][1], p[2][1]))
+	fillShape(img, box(minX, minY, maxX, maxY), func(x, y float64) bool {
+		d1, d2, d3 := side(p[0], p[1], x, y), side(p[1], p[2], x, y), side(p[2], p[0], x, y)
+		negative := d1 < 0 || d2 < 0 || d3 < 0
+		positive := d1 > 0 || d2 > 0 || d3 > 0
+		return !(negative && positive)
+	}, c)
+}
@@ -0,0 +1,156 @@
+// Package render draws checkers boards as SVG documents and raster images.
+package render
+
+import (
+	"fmt"
+	"github.com/batkinson/checkers-go/checkers"
+	"image/color"
+	"math"
+)
+
+const DEFAULT_SQUARE_SIZE = 48
+
+var (
+	LightSquare = color.RGBA{0xf0, 0xd9, 0xb5, 0xff}
+	DarkSquare  = color.RGBA{0x8b, 0x5a, 0x2b, 0xff}
+	Highlight   = color.RGBA{0xd8, 0xc0, 0x3c, 0xff}
+	BlackPiece  = color.RGBA{0x22, 0x22, 0x22, 0xff}
+	RedPiece    = color.RGBA{0xc0, 0x20, 0x20, 0xff}
+	Outline     = color.RGBA{0x0a, 0x0a, 0x0a, 0xff}
+	Crown       = color.RGBA{0xf5, 0xc5, 0x18, 0xff}
+	Arrow       = color.RGBA{0x20, 0x80, 0x30, 0xff}
+	Label       = color.RGBA{0x33, 0x33, 0x33, 0xff}
+	Background  = color.RGBA{0xff, 0xff, 0xff, 0xff}
+)
+
+var PieceColors = map[checkers.Player]color.RGBA{
+	checkers.BLACK_PLAYER: BlackPiece,
+	checkers.RED_PLAYER:   RedPiece,
+}
+
+// Options control how a board is drawn. The board is drawn with black's back
+// rank at the top unless Flip is set. Files are labelled a-h from red's left
+// and ranks 1-8 from red's back rank, as on a printed diagram. LastMove and
+// Arrows draw nothing for NO_MOVE, the zero Move or any move that stays put.
+type Options struct {
+	SquareSize    int
+	Flip          bool
+	Coordinates   bool
+	SquareNumbers bool
+	LastMove      checkers.Move
+	Arrows        []checkers.Move
+}
+
+func DefaultOptions() Options {
+	return Options{
+		SquareSize: DEFAULT_SQUARE_SIZE,
+		LastMove:   checkers.NO_MOVE,
+	}
+}
+
+type layout struct {
+	Options
+	margin int
+}
+
+func newLayout(opts Options) layout {
+	if opts.SquareSize <= 0 {
+		opts.SquareSize = DEFAULT_SQUARE_SIZE
+	}
+	l := layout{opts, 0}
+	if opts.Coordinates {
+		l.margin = opts.SquareSize / 2
+	}
+	return l
+}
+
+func (l layout) size() int {
+	return l.SquareSize*checkers.BOARD_DIM + 2*l.margin
+}
+
+// origin returns the pixel coordinates of the top left corner of pos.
+func (l layout) origin(pos checkers.Pos) (x, y int) {
+	col, row := pos.X, pos.Y
+	if l.Flip {
+		col, row = checkers.BOARD_DIM-1-col, checkers.BOARD_DIM-1-row
+	}
+	return l.margin + col*l.SquareSize, l.margin + row*l.SquareSize
+}
+
+func (l layout) center(pos checkers.Pos) (x, y float64) {
+	ox, oy := l.origin(pos)
+	half := float64(l.SquareSize) / 2
+	return float64(ox) + half, float64(oy) + half
+}
+
+// isMove reports whether move goes somewhere, so NO_MOVE and the zero Move
+// are not drawn.
+func isMove(move checkers.Move) bool {
+	return move != checkers.NO_MOVE && move.Src != move.Dst
+}
+
+func (l layout) highlighted(pos checkers.Pos) bool {
+	return isMove(l.LastMove) && (pos == l.LastMove.Src || pos == l.LastMove.Dst)
+}
+
+func (l layout) pieceRadius() float64 {
+	return float64(l.SquareSize) * 0.4
+}
+
+// arrowPoints returns the shaft end and the three corners of the head of an
+// arrow for move. An arrow that stays put collapses to a point.
+func (l layout) arrowPoints(move checkers.Move) (shaft [2][2]float64, head [3][2]float64) {
+	sx, sy := l.center(move.Src)
+	dx, dy := l.center(move.Dst)
+	length := math.Hypot(dx-sx, dy-sy)
+	if length == 0 {
+		return [2][2]float64{{sx, sy}, {sx, sy}}, [3][2]float64{{sx, sy}, {sx, sy}, {sx, sy}}
+	}
+	ux, uy := (dx-sx)/length, (dy-sy)/length
+	headLen := float64(l.SquareSize) * 0.35
+	headWidth := headLen * 0.6
+	bx, by := dx-ux*headLen, dy-uy*headLen
+	shaft = [2][2]float64{{sx, sy}, {bx, by}}
+	head = [3][2]float64{{dx, dy}, {bx - uy*headWidth, by + ux*headWidth}, {bx + uy*headWidth, by - ux*headWidth}}
+	return
+}
+
+func (l layout) arrowWidth() float64 {
+	return math.Max(2, float64(l.SquareSize)/10)
+}
+
+// fileLabel and rankLabel name the column and row of a board square.
+func fileLabel(x int) string {
+	return string(rune('a' + x))
+}
+
+func rankLabel(y int) string {
+	return fmt.Sprint(checkers.BOARD_DIM - y)
+}
+
+// labelPositions returns each column and row with the pixel offset of its
+// centre along the board edge.
+func (l layout) labelPositions() (files, ranks map[int]int) {
+	files, ranks = map[int]int{}, map[int]int{}
+	for i := 0; i < checkers.BOARD_DIM; i++ {
+		x, _ := l.origin(checkers.Pos{X: i, Y: 0})
+		_, y := l.origin(checkers.Pos{X: 0, Y: i})
+		files[i] = x + l.SquareSize/2
+		ranks[i] = y + l.SquareSize/2
+	}
+	return
+}
+
+// piecePositions lists the occupied squares in board order, so output does
+// not depend on map iteration order.
+func piecePositions(game *checkers.Game) []checkers.Pos {
+	positions := []checkers.Pos{}
+	for y := 0; y < checkers.BOARD_DIM; y++ {
+		for x := 0; x < checkers.BOARD_DIM; x++ {
+			if pos := (checkers.Pos{X: x, Y: y}); game.PieceAt(pos) {
+				positions = append(positions, pos)
+			}
+		}
+	}
+	return positions
+}
@@ -0,0 +1,129 @@
+package render
+
+import (
+	"bytes"
+	"github.com/batkinson/checkers-go/checkers"
+	"image/png"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestImage(t *testing.T) {
+	game := checkers.New()
+	opts := DefaultOptions()
+	img := Image(game, opts)
+	size := DEFAULT_SQUARE_SIZE * checkers.BOARD_DIM
+	if img.Bounds().Dx() != size || img.Bounds().Dy() != size {
+		t.Fatalf("expected %vx%v image, got %v", size, size, img.Bounds())
+	}
+	half := DEFAULT_SQUARE_SIZE / 2
+	if img.RGBAAt(DEFAULT_SQUARE_SIZE+half, half) != BlackPiece {
+		t.Errorf("expected black piece at top of board")
+	}
+	if img.RGBAAt(half, 7*DEFAULT_SQUARE_SIZE+half) != RedPiece {
+		t.Errorf("expected red piece at bottom of board")
+	}
+	if img.RGBAAt(half, half) != LightSquare || img.RGBAAt(half, 3*DEFAULT_SQUARE_SIZE+half) != DarkSquare {
+		t.Errorf("expected alternating light and dark squares")
+	}
+	opts.Flip = true
+	flipped := Image(game, opts)
+	if flipped.RGBAAt(DEFAULT_SQUARE_SIZE+half, half) != RedPiece {
+		t.Errorf("expected red piece at top of flipped board")
+	}
+}
+
+func TestImageHighlightsAndKings(t *testing.T) {
+	game := checkers.New()
+	move := checkers.Move{Src: checkers.Pos{X: 3, Y: 2}, Dst: checkers.Pos{X: 4, Y: 3}}
+	game.Move(move.Src, move.Dst)
+	game.Pieces[checkers.Pos{X: 0, Y: 3}] = checkers.Piece{Player: checkers.RED_PLAYER, King: true}
+	opts := DefaultOptions()
+	opts.LastMove = move
+	img := Image(game, opts)
+	if img.RGBAAt(3*DEFAULT_SQUARE_SIZE+2, 2*DEFAULT_SQUARE_SIZE+2) != Highlight {
+		t.Errorf("expected source of last move to be highlighted")
+	}
+	if img.RGBAAt(4*DEFAULT_SQUARE_SIZE+2, 3*DEFAULT_SQUARE_SIZE+2) != Highlight {
+		t.Errorf("expected destination of last move to be highlighted")
+	}
+	kingX := DEFAULT_SQUARE_SIZE / 2
+	kingY := 3*DEFAULT_SQUARE_SIZE + DEFAULT_SQUARE_SIZE/2
+	radius := DEFAULT_SQUARE_SIZE / 5
+	if img.RGBAAt(kingX+radius, kingY) != Crown {
+		t.Errorf("expected king to be marked with a crown ring")
+	}
+}
+
+func TestZeroOptions(t *testing.T) {
+	opts := Options{Arrows: []checkers.Move{{}, checkers.NO_MOVE}}
+	img := Image(checkers.New(), opts)
+	if img.RGBAAt(2, 2) == Highlight {
+		t.Errorf("expected no highlight without a last move")
+	}
+	var buf bytes.Buffer
+	if err := SVG(&buf, checkers.New(), opts); err != nil || strings.Contains(buf.String(), "<polygon") || strings.Contains(buf.String(), "NaN") {
+		t.Errorf("expected no arrows for moves that stay put, got %v", err)
+	}
+	l := newLayout(DefaultOptions())
+	shaft, head := l.arrowPoints(checkers.Move{Src: checkers.Pos{X: 1, Y: 0}, Dst: checkers.Pos{X: 1, Y: 0}})
+	if math.IsNaN(shaft[1][0]) || math.IsNaN(head[1][0]) {
+		t.Errorf("expected a zero-length arrow to collapse to a point, got %v %v", shaft, head)
+	}
+}
+
+func TestPNG(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Coordinates = true
+	opts.SquareNumbers = true
+	opts.Arrows = []checkers.Move{{Src: checkers.Pos{X: 3, Y: 2}, Dst: checkers.Pos{X: 4, Y: 3}}}
+	var buf bytes.Buffer
+	if err := PNG(&buf, checkers.New(), opts); err != nil {
+		t.Fatalf("expected png to encode: %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("expected png to decode: %v", err)
+	}
+	size := DEFAULT_SQUARE_SIZE*checkers.BOARD_DIM + DEFAULT_SQUARE_SIZE
+	if img.Bounds().Dx() != size {
+		t.Errorf("expected labelled board to be %v wide, got %v", size, img.Bounds().Dx())
+	}
+}
+
+func TestSVG(t *testing.T) {
+	game := checkers.New()
+	game.Pieces[checkers.Pos{X: 1, Y: 0}] = checkers.Piece{Player: checkers.BLACK_PLAYER, King: true}
+	opts := DefaultOptions()
+	opts.Coordinates = true
+	opts.SquareNumbers = true
+	opts.Arrows = []checkers.Move{{Src: checkers.Pos{X: 3, Y: 2}, Dst: checkers.Pos{X: 4, Y: 3}}}
+	var buf bytes.Buffer
+	if err := SVG(&buf, game, opts); err != nil {
+		t.Fatalf("expected svg to render: %v", err)
+	}
+	svg := buf.String()
+	if !strings.HasPrefix(svg, "<svg ") || !strings.HasSuffix(svg, "</svg>\n") {
+		t.Errorf("expected a complete svg document")
+	}
+	counts := map[string]int{
+		`fill="` + hex(BlackPiece) + `"`: 12,
+		`fill="` + hex(RedPiece) + `"`:   12,
+		`stroke="` + hex(Crown) + `"`:    1,
+		"<polygon":                       1,
+		">32</text>":                     1,
+		">a</text>":                      1,
+		`"middle">8</text>`:              1,
+	}
+	for fragment, count := range counts {
+		if strings.Count(svg, fragment) != count {
+			t.Errorf("expected %v occurrences of %v, got %v", count, fragment, strings.Count(svg, fragment))
+		}
+	}
+	var again bytes.Buffer
+	SVG(&again, game, opts)
+	if again.String() != svg {
+		t.Errorf("expected rendering to be deterministic")
+	}
+}
@@ -0,0 +1,72 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/batkinson/checkers-go/checkers"
+	"image/color"
+	"io"
+)
+
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// SVG writes the board as a standalone SVG document.
+func SVG(w io.Writer, game *checkers.Game, opts Options) error {
+	l := newLayout(opts)
+	out := bufio.NewWriter(w)
+	size := l.size()
+	fmt.Fprintf(out, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", size, size, size, size)
+	fmt.Fprintf(out, `<rect width="%d" height="%d" fill="%v"/>`+"\n", size, size, hex(Background))
+	for y := 0; y < checkers.BOARD_DIM; y++ {
+		for x := 0; x < checkers.BOARD_DIM; x++ {
+			pos := checkers.Pos{X: x, Y: y}
+			ox, oy := l.origin(pos)
+			fill := LightSquare
+			if checkers.Usable[pos] {
+				fill = DarkSquare
+			}
+			if l.highlighted(pos) {
+				fill = Highlight
+			}
+			fmt.Fprintf(out, `<rect x="%d" y="%d" width="%d" height="%d" fill="%v"/>`+"\n", ox, oy, l.SquareSize, l.SquareSize, hex(fill))
+			if l.SquareNumbers && checkers.Usable[pos] {
+				fmt.Fprintf(out, `<text x="%d" y="%d" font-family="sans-serif" font-size="%d" fill="%v">%d</text>`+"\n",
+					ox+2, oy+l.SquareSize/4+1, l.SquareSize/4, hex(Background), checkers.Square(pos))
+			}
+		}
+	}
+	for _, pos := range piecePositions(game) {
+		piece := game.Pieces[pos]
+		cx, cy := l.center(pos)
+		fmt.Fprintf(out, `<circle cx="%g" cy="%g" r="%g" fill="%v" stroke="%v" stroke-width="1"/>`+"\n",
+			cx, cy, l.pieceRadius(), hex(PieceColors[piece.Player]), hex(Outline))
+		if piece.King {
+			fmt.Fprintf(out, `<circle cx="%g" cy="%g" r="%g" fill="none" stroke="%v" stroke-width="%g"/>`+"\n",
+				cx, cy, l.pieceRadius()/2, hex(Crown), l.arrowWidth())
+		}
+	}
+	for _, move := range l.Arrows {
+		if !isMove(move) {
+			continue
+		}
+		shaft, head := l.arrowPoints(move)
+		fmt.Fprintf(out, `<line x1="%g" y1="%g" x2="%g" y2="%g" stroke="%v" stroke-width="%g" stroke-linecap="round"/>`+"\n",
+			shaft[0][0], shaft[0][1], shaft[1][0], shaft[1][1], hex(Arrow), l.arrowWidth())
+		fmt.Fprintf(out, `<polygon points="%g,%g %g,%g %g,%g" fill="%v"/>`+"\n",
+			head[0][0], head[0][1], head[1][0], head[1][1], head[2][0], head[2][1], hex(Arrow))
+	}
+	if l.Coordinates {
+		files, ranks := l.labelPositions()
+		fontSize := l.margin * 2 / 3
+		for i := 0; i < checkers.BOARD_DIM; i++ {
+			fmt.Fprintf(out, `<text x="%d" y="%d" font-family="sans-serif" font-size="%d" fill="%v" text-anchor="middle">%v</text>`+"\n",
+				files[i], size-l.margin/4, fontSize, hex(Label), fileLabel(i))
+			fmt.Fprintf(out, `<text x="%d" y="%d" font-family="sans-serif" font-size="%d" fill="%v" text-anchor="middle">%v</text>`+"\n",
+				l.margin/2, ranks[i]+fontSize/3, fontSize, hex(Label), rankLabel(i))
+		}
+	}
+	fmt.Fprintln(out, "</svg>")
+	return out.Flush()
+}
@@ -0,0 +1,139 @@
+package render
+
+import (
+	"bytes"
+	"github.com/batkinson/checkers-go/checkers"
+	"strings"
+)
+
+const (
+	ANSI_RESET     = "\x1b[0m"
+	ANSI_BOLD      = "\x1b[1m"
+	ANSI_LIGHT     = "\x1b[48;5;180m"
+	ANSI_DARK      = "\x1b[48;5;94m"
+	ANSI_HIGHLIGHT = "\x1b[48;5;178m"
+	ANSI_CURSOR    = "\x1b[48;5;33m"
+	ANSI_SELECTED  = "\x1b[48;5;71m"
+)
+
+var AnsiPieceColors = map[checkers.Player]string{
+	checkers.BLACK_PLAYER: "\x1b[38;5;16m",
+	checkers.RED_PLAYER:   "\x1b[38;5;160m",
+}
+
+var UnicodePieces = map[checkers.Piece]string{
+	{Player: checkers.RED_PLAYER, King: false}:   "⛀",
+	{Player: checkers.RED_PLAYER, King: true}:    "⛁",
+	{Player: checkers.BLACK_PLAYER, King: false}: "⛂",
+	{Player: checkers.BLACK_PLAYER, King: true}:  "⛃",
+}
+
+const (
+	EMPTY_DARK          = "."
+	EMPTY_LIGHT         = " "
+	UNICODE_EMPTY_DARK  = "·"
+	UNICODE_EMPTY_LIGHT = " "
+)
+
+// TextOptions control terminal rendering. Like Options, the board is drawn
+// with black's back rank at the top unless Flip is set. With Color, the
+// Cursor and Selected squares are highlighted, over the last move.
+type TextOptions struct {
+	Unicode     bool
+	Color       bool
+	Coordinates bool
+	Flip        bool
+	LastMove    checkers.Move
+	Cursor      checkers.Pos
+	Selected    checkers.Pos
+}
+
+func DefaultTextOptions() TextOptions {
+	return TextOptions{Unicode: true, Coordinates: true, LastMove: checkers.NO_MOVE, Cursor: checkers.NO_POS, Selected: checkers.NO_POS}
+}
+
+func (opts TextOptions) square(game *checkers.Game, pos checkers.Pos) string {
+	glyph := EMPTY_LIGHT
+	if opts.Unicode {
+		glyph = UNICODE_EMPTY_LIGHT
+	}
+	if checkers.Usable[pos] {
+		glyph = EMPTY_DARK
+		if opts.Unicode {
+			glyph = UNICODE_EMPTY_DARK
+		}
+	}
+	piece, occupied := game.Pieces[pos]
+	if occupied {
+		glyph = checkers.PieceStrings[piece.Player]
+		if piece.King {
+			glyph = strings.ToUpper(glyph)
+		}
+		if opts.Unicode {
+			glyph = UnicodePieces[piece]
+		}
+	}
+	if !opts.Color {
+		return glyph + " "
+	}
+	background := ANSI_LIGHT
+	if checkers.Usable[pos] {
+		background = ANSI_DARK
+	}
+	switch {
+	case pos == opts.Cursor:
+		background = ANSI_CURSOR
+	case pos == opts.Selected:
+		background = ANSI_SELECTED
+	case isMove(opts.LastMove) && (pos == opts.LastMove.Src || pos == opts.LastMove.Dst):
+		background = ANSI_HIGHLIGHT
+	}
+	if occupied {
+		foreground := AnsiPieceColors[piece.Player]
+		if piece.King {
+			foreground = ANSI_BOLD + foreground
+		}
+		return background + foreground + glyph + " " + ANSI_RESET
+	}
+	return background + glyph + " " + ANSI_RESET
+}
+
+// Lines renders the board as one string per screen row, top row first.
+func Lines(game *checkers.Game, opts TextOptions) []string {
+	lines := []string{}
+	for row := 0; row < checkers.BOARD_DIM; row++ {
+		var line bytes.Buffer
+		y := row
+		if opts.Flip {
+			y = checkers.BOARD_DIM - 1 - row
+		}
+		if opts.Coordinates {
+			line.WriteString(rankLabel(y) + " ")
+		}
+		for col := 0; col < checkers.BOARD_DIM; col++ {
+			x := col
+			if opts.Flip {
+				x = checkers.BOARD_DIM - 1 - col
+			}
+			line.WriteString(opts.square(game, checkers.Pos{X: x, Y: y}))
+		}
+		lines = append(lines, strings.TrimRight(line.String(), " "))
+	}
+	if opts.Coordinates {
+		files := make([]string, checkers.BOARD_DIM)
+		for col := range files {
+			x := col
+			if opts.Flip {
+				x = checkers.BOARD_DIM - 1 - col
+			}
+			files[col] = fileLabel(x)
+		}
+		lines = append(lines, "  "+strings.Join(files, " "))
+	}
+	return lines
+}
+
+// Text renders the board as a multi-line string for a terminal.
+func Text(game *checkers.Game, opts TextOptions) string {
+	return strings.Join(Lines(game, opts), "\n") + "\n"
+}
@@ -0,0 +1,69 @@
+package render
+
+import (
+	"github.com/batkinson/checkers-go/checkers"
+	"strings"
+	"testing"
+)
+
+func TestText(t *testing.T) {
+	game := checkers.New()
+	game.Pieces[checkers.Pos{X: 0, Y: 3}] = checkers.Piece{Player: checkers.RED_PLAYER, King: true}
+	opts := DefaultTextOptions()
+	opts.Unicode = false
+	lines := Lines(game, opts)
+	expected := []string{
+		"8   b   b   b   b",
+		"7 b   b   b   b",
+		"6   b   b   b   b",
+		"5 R   .   .   .",
+		"4   .   .   .   .",
+		"3 r   r   r   r",
+		"2   r   r   r   r",
+		"1 r   r   r   r",
+		"  a b c d e f g h",
+	}
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected\n%v\ngot\n%v", strings.Join(expected, "\n"), strings.Join(lines, "\n"))
+	}
+	opts.Flip = true
+	lines = Lines(game, opts)
+	if lines[0] != "1   r   r   r   r" || lines[8] != "  h g f e d c b a" {
+		t.Errorf("expected flipped board to start from rank 1, got %v", lines)
+	}
+}
+
+func TestTextUnicodeAndColor(t *testing.T) {
+	game := checkers.New()
+	opts := DefaultTextOptions()
+	text := Text(game, opts)
+	if strings.Count(text, UnicodePieces[checkers.Piece{Player: checkers.BLACK_PLAYER}]) != 12 ||
+		strings.Count(text, UnicodePieces[checkers.Piece{Player: checkers.RED_PLAYER}]) != 12 {
+		t.Errorf("expected 12 unicode pieces per side, got\n%v", text)
+	}
+	if strings.Contains(text, "\x1b") {
+		t.Errorf("expected no escape codes without colour")
+	}
+	opts.Color = true
+	opts.LastMove = checkers.Move{Src: checkers.Pos{X: 1, Y: 0}, Dst: checkers.Pos{X: 3, Y: 0}}
+	lines := Lines(game, opts)
+	if strings.Count(lines[0], ANSI_HIGHLIGHT) != 2 || !strings.Contains(lines[0], AnsiPieceColors[checkers.BLACK_PLAYER]) {
+		t.Errorf("expected highlighted squares and coloured pieces, got %q", lines[0])
+	}
+	if strings.Count(lines[0], ANSI_RESET) != checkers.BOARD_DIM {
+		t.Errorf("expected every square to reset its colours, got %q", lines[0])
+	}
+}
+
+func TestTextCursor(t *testing.T) {
+	game := checkers.New()
+	opts := DefaultTextOptions()
+	opts.Color = true
+	opts.LastMove = checkers.Move{Src: checkers.Pos{X: 1, Y: 0}, Dst: checkers.Pos{X: 3, Y: 0}}
+	opts.Cursor = checkers.Pos{X: 1, Y: 0}
+	opts.Selected = checkers.Pos{X: 5, Y: 0}
+	lines := Lines(game, opts)
+	if strings.Count(lines[0], ANSI_CURSOR) != 1 || strings.Count(lines[0], ANSI_SELECTED) != 1 || strings.Count(lines[0], ANSI_HIGHLIGHT) != 1 {
+		t.Errorf("expected the cursor over one end of the last move and a selected square, got %q", lines[0])
+	}
+}
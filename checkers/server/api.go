@@ -0,0 +1,429 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/batkinson/checkers-go/checkers"
+	"github.com/batkinson/checkers-go/checkers/pdn"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// API_PATH is where ServeHTTP answers the JSON API:
+//
+//	GET  /games             games waiting for a player or open to spectators
+//	GET  /games/{id}        a game's board, turn, players and result
+//	POST /games             create a game and take a seat in it
+//	POST /games/{id}/moves  move in a game, authorized by the seat's token
+const (
+	API_PATH         = "/games"
+	MAX_REQUEST_BODY = 64 * 1024
+	SEAT_TIMEOUT     = time.Minute
+)
+
+var (
+	errExpectedMove       = errors.New("expected a move such as 11-15 or 22x15x8")
+	errInvalidRequestBody = errors.New("invalid request body")
+	errMethodNotAllowed   = errors.New("method not allowed")
+)
+
+// GameSummary is a game as GET /games lists it.
+type GameSummary struct {
+	Id          string `json:"id"`
+	NeedsPlayer bool   `json:"needs_player"`
+	CanSpectate bool   `json:"can_spectate"`
+}
+
+// GameDetail is a game as GET /games/{id} returns it. Turn is "waiting" until
+// both seats are filled, and Players maps each seated colour to "connected",
+// or to "disconnected" while the seat is held for its player to resume.
+type GameDetail struct {
+	Id         string            `json:"id"`
+	Board      string            `json:"board"`
+	FEN        string            `json:"fen"`
+	Turn       string            `json:"turn"`
+	Players    map[string]string `json:"players"`
+	Spectators int               `json:"spectators"`
+	Ballot     string            `json:"ballot,omitempty"`
+	LastMove   string            `json:"last_move,omitempty"`
+	LegalMoves []string          `json:"legal_moves"`
+	Winner     string            `json:"winner,omitempty"`
+}
+
+// Seat answers POST /games with the game created, the colour the caller plays
+// and the token that authorizes its moves.
+type Seat struct {
+	GameDetail
+	Player string `json:"player"`
+	Token  string `json:"token"`
+}
+
+// NewGameRequest is the optional body of POST /games. Ballot is a ballot's
+// number or moves, or "random"; Position is a board string or FEN, with Turn
+// the colour to move. They correspond to NEW BALLOT and NEW POSITION.
+type NewGameRequest struct {
+	Ballot   string `json:"ballot"`
+	Position string `json:"position"`
+	Turn     string `json:"turn"`
+}
+
+// MoveRequest is the body of POST /games/{id}/moves.
+type MoveRequest struct {
+	Move string `json:"move"`
+}
+
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// detail describes the game. It must run on the game's goroutine.
+func (game *Game) detail() GameDetail {
+	detail := GameDetail{
+		Id:         game.Id,
+		Board:      game.GameState.String(),
+		FEN:        pdn.FEN(game.GameState),
+		Turn:       game.Turn(),
+		Players:    map[string]string{},
+		Spectators: len(game.Spectators),
+		LegalMoves: []string{},
+	}
+	for player, client := range game.Players {
+		detail.Players[player.Color] = "connected"
+		if client == nil {
+			detail.Players[player.Color] = "disconnected"
+		}
+	}
+	if game.Ballot != checkers.NO_BALLOT {
+		detail.Ballot = game.Ballot.String()
+	}
+	if game.LastMove != checkers.NO_MOVE {
+		detail.LastMove = game.LastMove.String()
+	}
+	if game.HasWinner() {
+		detail.Winner = game.Winner()
+	} else if game.SeatsFilled() {
+		for _, move := range game.GameState.LegalMoves() {
+			detail.LegalMoves = append(detail.LegalMoves, move.String())
+		}
+	}
+	return detail
+}
+
+func (game *Game) fetchDetail() (detail GameDetail, err error) {
+	err = game.do(func() error {
+		detail = game.detail()
+		return nil
+	})
+	return detail, gameError(err, errNoSuchGame)
+}
+
+// summaries lists the games with an open seat or room for spectators, by id.
+func (lobby *Lobby) summaries() []GameSummary {
+	summaries := []GameSummary{}
+	lobby.do(func() error {
+		for gameId, game := range lobby.games {
+			listing := game.Listing()
+			if listing.NeedsPlayer || listing.CanSpectate {
+				summaries = append(summaries, GameSummary{gameId, listing.NeedsPlayer, listing.CanSpectate})
+			}
+		}
+		return nil
+	})
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Id < summaries[j].Id })
+	return summaries
+}
+
+// httpPlayer holds a seat for requests rather than a connection. Its client
+// has no connection and the messages sent to it are discarded. The seat is
+// given up once the game has been won for the resume grace period, so the
+// result can still be fetched, or after seatTimeout without a request, when
+// it is held like a dropped connection's and the token can RESUME it. Each
+// seat counts against Config.MaxClients until it is given up.
+type httpPlayer struct {
+	server *Server
+	client *Client
+	token  string
+	idle   Timer
+}
+
+func (server *Server) newHTTPPlayer() (*httpPlayer, error) {
+	if server.slots != nil {
+		select {
+		case server.slots <- true:
+		default:
+			return nil, errServerFull
+		}
+	}
+	conn, other := net.Pipe()
+	other.Close()
+	player := &httpPlayer{server: server, client: server.newClient(conn)}
+	go player.discard()
+	return player, nil
+}
+
+// seatTimeout is how long a seat is kept without a request: Config.IdleTimeout,
+// or the resume grace period when that is zero, so an abandoned seat is always
+// given up. SEAT_TIMEOUT applies when both are zero.
+func (server *Server) seatTimeout() time.Duration {
+	switch {
+	case server.Config.IdleTimeout > 0:
+		return server.Config.IdleTimeout
+	case server.Config.ResumeGrace > 0:
+		return server.Config.ResumeGrace
+	}
+	return SEAT_TIMEOUT
+}
+
+// discard drops the messages sent to the player until its client closes,
+// then frees its slot.
+func (player *httpPlayer) discard() {
+	if player.server.slots != nil {
+		defer func() { <-player.server.slots }()
+	}
+	for {
+		select {
+		case message := <-player.client.Messages:
+			if strings.HasPrefix(message, "STATUS WINNER") {
+				player.server.Clock.AfterFunc(player.server.Config.ResumeGrace, player.drop)
+			}
+		case <-player.client.Closing:
+			return
+		}
+	}
+}
+
+// seat takes a seat in a new game created with the NEW arguments.
+func (player *httpPlayer) seat(args ...string) (seat Seat, err error) {
+	if err := newGame(player.client, args...); err != nil {
+		player.client.Close()
+		return seat, err
+	}
+	game, _ := player.client.Membership()
+	err = game.do(func() error {
+		for color, client := range game.Players {
+			if client == player.client {
+				seat = Seat{game.detail(), color.Color, game.Sessions[color].Token}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		disconnect(player.client)
+		return seat, gameError(err, errNoSuchGame)
+	}
+	player.token = seat.Token
+	server := player.server
+	server.lock.Lock()
+	player.idle = server.Clock.AfterFunc(server.seatTimeout(), player.drop)
+	server.httpPlayers[player.token] = player
+	server.lock.Unlock()
+	server.Logger.Println("seating", "http", seat.Id, seat.Player)
+	return seat, nil
+}
+
+// drop gives up the player's seat as if its connection had ended.
+func (player *httpPlayer) drop() {
+	server := player.server
+	server.lock.Lock()
+	if server.httpPlayers[player.token] != player {
+		server.lock.Unlock()
+		return
+	}
+	delete(server.httpPlayers, player.token)
+	player.idle.Stop()
+	server.lock.Unlock()
+	disconnect(player.client)
+}
+
+func (server *Server) findHTTPPlayer(token string) *httpPlayer {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+	player := server.httpPlayers[token]
+	if player != nil {
+		player.idle.Stop()
+		player.idle = server.Clock.AfterFunc(server.seatTimeout(), player.drop)
+	}
+	return player
+}
+
+// dropHTTPPlayers gives up every seat held over HTTP.
+func (server *Server) dropHTTPPlayers() {
+	server.lock.Lock()
+	players := []*httpPlayer{}
+	for _, player := range server.httpPlayers {
+		players = append(players, player)
+	}
+	server.lock.Unlock()
+	for _, player := range players {
+		player.drop()
+	}
+}
+
+// httpStatus is the HTTP status for a command's error.
+func httpStatus(err error) int {
+	switch {
+	case errors.Is(err, errNoSuchGame):
+		return http.StatusNotFound
+	case errors.Is(err, errNoSuchSession), errors.Is(err, errNotPlaying):
+		return http.StatusForbidden
+	case errors.Is(err, errNotYourTurn), errors.Is(err, errGameOver):
+		return http.StatusConflict
+	case errors.Is(err, errMethodNotAllowed):
+		return http.StatusMethodNotAllowed
+	case errors.Is(err, ErrServerClosed), errors.Is(err, errServerFull):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusBadRequest
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	code := errorCode(err)
+	if errors.Is(err, ErrServerClosed) {
+		code = "SERVER_CLOSED"
+	}
+	writeJSON(w, httpStatus(err), apiError{code, err.Error()})
+}
+
+// readJSON decodes an optional request body into value.
+func readJSON(w http.ResponseWriter, r *http.Request, value interface{}) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, MAX_REQUEST_BODY))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(value); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: %v", errInvalidRequestBody, err)
+	}
+	return nil
+}
+
+// serveAPI routes requests under API_PATH.
+func (server *Server) serveAPI(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, API_PATH), "/"), "/")
+	switch {
+	case parts[0] == "" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, server.lobby.summaries())
+	case parts[0] == "" && r.Method == http.MethodPost:
+		server.createGame(w, r)
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		server.getGame(w, parts[0])
+	case len(parts) == 2 && parts[1] == "moves" && r.Method == http.MethodPost:
+		server.postMove(w, r, parts[0])
+	case len(parts) <= 1 || len(parts) == 2 && parts[1] == "moves":
+		writeError(w, errMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (server *Server) getGame(w http.ResponseWriter, gameId string) {
+	game := server.lobby.find(gameId)
+	if game == nil {
+		writeError(w, fmt.Errorf("%w: %v", errNoSuchGame, gameId))
+		return
+	}
+	detail, err := game.fetchDetail()
+	if err != nil {
+		writeError(w, fmt.Errorf("%w: %v", errNoSuchGame, gameId))
+		return
+	}
+	writeJSON(w, http.StatusOK, detail)
+}
+
+func (server *Server) createGame(w http.ResponseWriter, r *http.Request) {
+	var request NewGameRequest
+	if err := readJSON(w, r, &request); err != nil {
+		writeError(w, err)
+		return
+	}
+	args := []string{}
+	switch {
+	case request.Ballot != "" && request.Position != "":
+		writeError(w, fmt.Errorf("%w: expected a ballot or a position, not both", errInvalidRequestBody))
+		return
+	case request.Ballot == "random":
+		args = []string{"BALLOT"}
+	case request.Ballot != "":
+		args = append([]string{"BALLOT"}, strings.Fields(request.Ballot)...)
+	case request.Position != "":
+		args = []string{"POSITION", request.Position}
+		if request.Turn != "" {
+			args = append(args, request.Turn)
+		}
+	}
+	player, err := server.newHTTPPlayer()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	seat, err := player.seat(args...)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Location", API_PATH+"/"+seat.Id)
+	writeJSON(w, http.StatusCreated, seat)
+}
+
+// playMove makes a move in PDN notation, such as 11-15 or 22x15x8, for the
+// client. Every step is checked on a copy of the board first, so a multi-jump
+// is made in full or not at all. It returns the game as the move leaves it.
+func playMove(client *Client, text string) (detail GameDetail, err error) {
+	err = playing(client, func(game *Game) error {
+		if game.HasWinner() {
+			return errGameOver
+		}
+		if !game.TurnIs(client) {
+			return errNotYourTurn
+		}
+		steps, err := pdn.Apply(game.GameState.Clone(), text)
+		if err != nil && errorCode(err) == UNKNOWN_ERROR {
+			return fmt.Errorf("%w: %v", errExpectedMove, err)
+		} else if err != nil {
+			return err
+		}
+		for _, step := range steps {
+			if _, err := game.GameState.Move(step.Src, step.Dst); err != nil {
+				return err
+			}
+			game.Broadcast(fmt.Sprintf("STATUS TURN %v", game.Turn()))
+		}
+		detail = game.detail()
+		return nil
+	})
+	return detail, err
+}
+
+func (server *Server) postMove(w http.ResponseWriter, r *http.Request, gameId string) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	player := server.findHTTPPlayer(token)
+	if player == nil {
+		writeError(w, fmt.Errorf("%w: expected Authorization: Bearer <token>", errNoSuchSession))
+		return
+	}
+	game, _ := player.client.Membership()
+	if game == nil || game.Id != gameId {
+		writeError(w, errNotPlaying)
+		return
+	}
+	var request MoveRequest
+	if err := readJSON(w, r, &request); err != nil {
+		writeError(w, err)
+		return
+	}
+	detail, err := playMove(player.client, request.Move)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, detail)
+}
@@ -0,0 +1,235 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/batkinson/checkers-go/checkers"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// call makes an API request with an optional JSON body and bearer token,
+// decoding the response into reply, and returns the response.
+func call(t *testing.T, server *Server, method, path, token string, body, reply interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	var content bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&content).Encode(body)
+	}
+	request := httptest.NewRequest(method, path, &content)
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, request)
+	if reply != nil {
+		if err := json.Unmarshal(response.Body.Bytes(), reply); err != nil {
+			t.Fatalf("expected JSON from %v %v, got %q: %v", method, path, response.Body, err)
+		}
+	}
+	return response
+}
+
+// expectError checks that a request fails with status and code.
+func expectError(t *testing.T, server *Server, method, path, token string, body interface{}, status int, code string) {
+	t.Helper()
+	var reply apiError
+	if response := call(t, server, method, path, token, body, &reply); response.Code != status || reply.Code != code {
+		t.Errorf("expected %v %v from %v %v, got %v %v", status, code, method, path, response.Code, reply)
+	}
+}
+
+// startAPIServer starts a server whose HTTP seats outlast a test.
+func startAPIServer(t *testing.T) *Server {
+	server := startServer(t, SLOW_DISCONNECT)
+	server.Config.IdleTimeout = TEST_TIMEOUT
+	return server
+}
+
+// listenAPI serves server over TCP and returns the address.
+func listenAPI(t *testing.T, server *Server) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(listener)
+	return listener.Addr().String()
+}
+
+// through reads lines until the reply to a command.
+func (w *wire) through(reply string) {
+	w.t.Helper()
+	for {
+		line, err := w.next()
+		if err != nil {
+			w.t.Fatalf("expected %q, got %v", reply, err)
+		}
+		if line == reply {
+			return
+		}
+	}
+}
+
+func TestAPIPlay(t *testing.T) {
+	server := startAPIServer(t)
+	terminal := dialWire(t, listenAPI(t, server))
+	var games []GameSummary
+	if response := call(t, server, "GET", API_PATH, "", nil, &games); response.Code != http.StatusOK || len(games) != 0 {
+		t.Fatalf("expected no games, got %v %v", response.Code, games)
+	}
+	var seat Seat
+	response := call(t, server, "POST", API_PATH, "", nil, &seat)
+	if response.Code != http.StatusCreated || response.Header().Get("Location") != API_PATH+"/"+seat.Id || len(seat.Token) != 32 {
+		t.Fatalf("expected a seat, got %v %v %+v", response.Code, response.Header(), seat)
+	}
+	if seat.Turn != "waiting" || len(seat.Players) != 1 || len(seat.LegalMoves) != 0 {
+		t.Errorf("expected a game waiting for a player, got %+v", seat.GameDetail)
+	}
+	call(t, server, "GET", API_PATH, "", nil, &games)
+	if len(games) != 1 || games[0].Id != seat.Id || !games[0].NeedsPlayer {
+		t.Errorf("expected the game to need a player, got %v", games)
+	}
+	board := checkers.New().String()
+	terminal.join(seat.Id, seat.Player, board)
+	var detail GameDetail
+	call(t, server, "GET", API_PATH+"/"+seat.Id, "", nil, &detail)
+	if detail.Turn != checkers.BLACK || len(detail.Players) != 2 || detail.Players[checkers.RED] != "connected" || len(detail.LegalMoves) != 7 {
+		t.Errorf("expected black to have 7 moves, got %+v", detail)
+	}
+	moves := API_PATH + "/" + seat.Id + "/moves"
+	if seat.Player != checkers.BLACK {
+		t.Fatalf("expected the creator to play black, got %v", seat.Player)
+	}
+	expectError(t, server, "POST", moves, seat.Token, MoveRequest{"9-"}, http.StatusBadRequest, "EXPECTED_MOVE")
+	call(t, server, "POST", moves, seat.Token, MoveRequest{"9-14"}, &detail)
+	terminal.expect("STATUS MOVED 1 2 2 3", "STATUS TURN red")
+	if detail.Turn != checkers.RED || detail.LastMove != "9-14" {
+		t.Errorf("expected red to move after 9-14, got %+v", detail)
+	}
+	expectError(t, server, "POST", moves, seat.Token, MoveRequest{"10-15"}, http.StatusConflict, "NOT_YOUR_TURN")
+	expectError(t, server, "POST", moves, "", MoveRequest{"9-14"}, http.StatusForbidden, "NO_SUCH_SESSION")
+	expectError(t, server, "POST", moves, seat.Token, map[string]string{"from": "9"}, http.StatusBadRequest, "INVALID_REQUEST_BODY")
+	expectError(t, server, "POST", API_PATH+"/nosuchgame/moves", seat.Token, MoveRequest{"9-14"}, http.StatusForbidden, "NOT_PLAYING")
+	expectError(t, server, "GET", API_PATH+"/nosuchgame", "", nil, http.StatusNotFound, "NO_SUCH_GAME")
+	expectError(t, server, "DELETE", API_PATH, "", nil, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED")
+	expectError(t, server, "POST", API_PATH, "", NewGameRequest{Ballot: "9999"}, http.StatusBadRequest, "NO_SUCH_BALLOT")
+}
+
+func TestAPIWin(t *testing.T) {
+	server := startAPIServer(t)
+	terminal := dialWire(t, listenAPI(t, server))
+	var seat Seat
+	call(t, server, "POST", API_PATH, "", NewGameRequest{Position: WIN_POSITION, Turn: checkers.BLACK}, &seat)
+	if seat.Board != WIN_POSITION {
+		t.Fatalf("expected the position, got %+v", seat)
+	}
+	terminal.join(seat.Id, seat.Player, WIN_POSITION)
+	moves := API_PATH + "/" + seat.Id + "/moves"
+	var detail GameDetail
+	call(t, server, "POST", moves, seat.Token, MoveRequest{"17x26"}, &detail)
+	terminal.send("MOVE 4 7 2 5")
+	terminal.through("OK")
+	call(t, server, "GET", API_PATH+"/"+seat.Id, "", nil, &detail)
+	if detail.Winner != checkers.RED || len(detail.LegalMoves) != 0 {
+		t.Errorf("expected red to win, got %+v", detail)
+	}
+	deadline := time.Now().Add(TEST_TIMEOUT)
+	for server.findHTTPPlayer(seat.Token) != nil {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the seat to be given up after the game")
+		}
+		time.Sleep(TEST_GRACE / 10)
+	}
+	expectError(t, server, "POST", moves, seat.Token, MoveRequest{"9-14"}, http.StatusForbidden, "NO_SUCH_SESSION")
+}
+
+func TestAPIMultiJump(t *testing.T) {
+	server := startAPIServer(t)
+	terminal := dialWire(t, listenAPI(t, server))
+	position := "********|********|*b******|**r*****|********|****r***|********|********"
+	var seat Seat
+	call(t, server, "POST", API_PATH, "", NewGameRequest{Position: position, Turn: checkers.BLACK}, &seat)
+	terminal.join(seat.Id, seat.Player, position)
+	var detail GameDetail
+	call(t, server, "POST", API_PATH+"/"+seat.Id+"/moves", seat.Token, MoveRequest{"9x18x27"}, &detail)
+	terminal.expect("STATUS MOVED 1 2 3 4", "STATUS CAPTURED 2 3", "STATUS TURN black")
+	terminal.expect("STATUS MOVED 3 4 5 6", "STATUS CAPTURED 4 5")
+	if detail.Winner != checkers.BLACK || detail.LastMove != "18x27" {
+		t.Errorf("expected black to win with a double jump, got %+v", detail)
+	}
+}
+
+func TestAPIMultiJumpAllOrNothing(t *testing.T) {
+	server := startAPIServer(t)
+	position := "********|********|*b******|**r*****|********|****r***|********|********"
+	var seat Seat
+	call(t, server, "POST", API_PATH, "", NewGameRequest{Position: position, Turn: checkers.BLACK}, &seat)
+	dialWire(t, listenAPI(t, server)).join(seat.Id, seat.Player, position)
+	expectError(t, server, "POST", API_PATH+"/"+seat.Id+"/moves", seat.Token, MoveRequest{"9x18x25"}, http.StatusBadRequest, "EXPECTED_MOVE")
+	var detail GameDetail
+	call(t, server, "GET", API_PATH+"/"+seat.Id, "", nil, &detail)
+	if detail.Board != position || detail.Turn != checkers.BLACK || detail.LastMove != "" {
+		t.Errorf("expected the board unchanged after an illegal second jump, got %+v", detail)
+	}
+}
+
+func TestAPIShutdown(t *testing.T) {
+	server := startAPIServer(t)
+	var seat Seat
+	call(t, server, "POST", API_PATH, "", nil, &seat)
+	if err := server.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if server.findHTTPPlayer(seat.Token) != nil {
+		t.Errorf("expected seats held over HTTP to be given up")
+	}
+	expectError(t, server, "POST", API_PATH, "", nil, http.StatusServiceUnavailable, "SERVER_CLOSED")
+}
+
+func TestAPIAbandonedSeat(t *testing.T) {
+	server := startServer(t, SLOW_DISCONNECT)
+	server.Config.MaxClients = 1
+	var seat Seat
+	if response := call(t, server, "POST", API_PATH, "", nil, &seat); response.Code != http.StatusCreated {
+		t.Fatalf("expected a seat, got %v", response.Code)
+	}
+	expectError(t, server, "POST", API_PATH, "", nil, http.StatusServiceUnavailable, "SERVER_FULL")
+	deadline := time.Now().Add(TEST_TIMEOUT)
+	for server.lobby.find(seat.Id) != nil {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the abandoned seat and its game to be given up")
+		}
+		time.Sleep(TEST_GRACE / 10)
+	}
+	for call(t, server, "POST", API_PATH, "", nil, nil).Code != http.StatusCreated {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the abandoned seat's slot to be freed")
+		}
+		time.Sleep(TEST_GRACE / 10)
+	}
+}
+
+func TestHTTPTimeout(t *testing.T) {
+	server := startAPIServer(t)
+	server.Config.HTTPTimeout = TEST_GRACE
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.ServeHTTPListener(listener)
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.Write([]byte("GET " + API_PATH + " HTTP/1.1\r\nHost: checkers\r\n"))
+	conn.SetReadDeadline(time.Now().Add(TEST_TIMEOUT))
+	if _, err := conn.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("expected a request that never finishes to be closed, got %v", err)
+	}
+}
@@ -0,0 +1,233 @@
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// STALL_NOTICE_TIMEOUT bounds the attempt to tell a slow client why it is
+// being disconnected.
+const STALL_NOTICE_TIMEOUT = time.Second
+
+// boardUpdates are the messages a resync replaces, and so the only ones the
+// coalesce policy discards.
+var boardUpdates = []string{"STATUS MOVED", "STATUS CAPTURED", "STATUS KING", "STATUS TURN", "STATUS BOARD"}
+
+// Client is one connection. Its commands are read and run one at a time on
+// the connection's own goroutine, and its messages are written by another.
+type Client struct {
+	server   *Server
+	Conn     net.Conn
+	Messages chan string
+	Closing  chan bool
+	Stalled  chan bool
+	Policy   string
+	missed   int32
+	// lock guards the game the client is in, which the game itself updates,
+	// and whether the client has stalled, which any game may discover.
+	lock    sync.Mutex
+	game    *Game
+	playing bool
+	stalled bool
+	// deadline orders stalling against the writer setting its deadline, so a
+	// write in progress is always interrupted.
+	deadline sync.Mutex
+}
+
+func (server *Server) newClient(c net.Conn) *Client {
+	return &Client{
+		server:   server,
+		Conn:     c,
+		Messages: make(chan string, server.Config.ClientQueue),
+		Closing:  make(chan bool),
+		Stalled:  make(chan bool),
+		Policy:   server.Config.SlowClients,
+	}
+}
+
+// Membership returns the game the client is in, if any, and whether it is
+// playing rather than spectating.
+func (client *Client) Membership() (*Game, bool) {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	return client.game, client.playing
+}
+
+func (client *Client) setMembership(game *Game, playing bool) {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	client.game, client.playing = game, playing
+}
+
+func isBoardUpdate(message string) bool {
+	for _, prefix := range boardUpdates {
+		if strings.HasPrefix(message, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// send queues a message for the client without waiting. When the queue is
+// full the client's policy decides what happens.
+func (client *Client) send(message string) {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	if client.stalled {
+		return
+	}
+	select {
+	case client.Messages <- message:
+		return
+	default:
+	}
+	switch {
+	case client.Policy == SLOW_COALESCE && isBoardUpdate(message):
+		if atomic.SwapInt32(&client.missed, 1) == 0 {
+			client.server.Logger.Println("coalescing", client.Conn.RemoteAddr())
+		}
+	case client.Policy == SLOW_DROP:
+		client.server.Logger.Println("dropping", client.Conn.RemoteAddr())
+		client.stalled = true
+		client.Conn.Close()
+	default:
+		client.server.Logger.Println("stalled", client.Conn.RemoteAddr())
+		client.stalled = true
+		client.deadline.Lock()
+		close(client.Stalled)
+		client.Conn.SetWriteDeadline(time.Now())
+		client.deadline.Unlock()
+	}
+}
+
+// resync sends the current state of the client's game, replacing the board
+// updates discarded while its queue was full.
+func (client *Client) resync() {
+	game, _ := client.Membership()
+	if game == nil {
+		return
+	}
+	game.do(func() error {
+		if game.IsPlayer(client) || game.IsSpectator(client) {
+			client.send(fmt.Sprintf("STATUS BOARD %v", game.GameState))
+			client.send(fmt.Sprintf("STATUS TURN %v", game.Turn()))
+		}
+		return nil
+	})
+}
+
+// Close stops the client's responses once the messages already queued have
+// been written, then closes its connection.
+func (client *Client) Close() {
+	client.server.Logger.Println("closing", client.Conn.RemoteAddr())
+	close(client.Closing)
+}
+
+func (client *Client) serviceResponses() {
+	defer client.Conn.Close()
+	failed := false
+	isStalled := func() bool {
+		select {
+		case <-client.Stalled:
+			return true
+		default:
+			return false
+		}
+	}
+	write := func(message string) {
+		client.deadline.Lock()
+		if failed || isStalled() {
+			client.deadline.Unlock()
+			return
+		}
+		if client.server.Config.WriteTimeout > 0 {
+			client.Conn.SetWriteDeadline(time.Now().Add(client.server.Config.WriteTimeout))
+		}
+		client.deadline.Unlock()
+		if _, err := client.Conn.Write([]byte(message + "\r\n")); err != nil {
+			// Closing the connection ends the reader, which disconnects the
+			// client. Messages are discarded until then.
+			failed = true
+			if !isStalled() {
+				client.Conn.Close()
+			}
+		}
+	}
+	stalled := client.Stalled
+	for {
+		select {
+		case <-stalled:
+			stalled = nil
+			client.Conn.SetWriteDeadline(time.Now().Add(STALL_NOTICE_TIMEOUT))
+			client.Conn.Write([]byte(fmt.Sprintf("ERROR %v %v\r\n", errorCode(errSlowClient), errSlowClient)))
+			failed = true
+			client.Conn.Close()
+			continue
+		default:
+		}
+		select {
+		case message := <-client.Messages:
+			write(message)
+			if len(client.Messages) == 0 && atomic.CompareAndSwapInt32(&client.missed, 1, 0) {
+				client.resync()
+			}
+		case <-stalled:
+		case <-client.Closing:
+			for {
+				select {
+				case message := <-client.Messages:
+					write(message)
+				default:
+					client.server.Logger.Println("shutdown", client.Conn.RemoteAddr())
+					return
+				}
+			}
+		}
+	}
+}
+
+// serve runs the client's commands until its connection ends, answering each
+// with OK or ERROR.
+func (client *Client) serve() {
+	defer disconnect(client)
+	client.server.active.Add(1)
+	go func() {
+		defer client.server.active.Done()
+		client.serviceResponses()
+	}()
+	lines := bufio.NewReader(client.Conn)
+	for {
+		if client.server.Config.IdleTimeout > 0 {
+			client.Conn.SetReadDeadline(time.Now().Add(client.server.Config.IdleTimeout))
+		}
+		line, err := lines.ReadString(byte('\n'))
+		if err != nil {
+			break
+		}
+		fields := strings.Fields(strings.TrimSpace(line))
+		if len(fields) < 1 {
+			break
+		}
+		cmd, args := fields[0], fields[1:]
+		if err := serviceCommand(client, cmd, args); err != nil {
+			client.send(fmt.Sprintf("ERROR %v %v", errorCode(err), err))
+		} else {
+			client.send("OK")
+		}
+		if cmd == "QUIT" {
+			break
+		}
+	}
+}
+
+func serviceCommand(client *Client, cmd string, args []string) error {
+	if executor, cmdSupported := supportedCommands[cmd]; cmdSupported {
+		return executor(client, args...)
+	}
+	return errInvalidCommand
+}
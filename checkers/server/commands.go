@@ -0,0 +1,397 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"github.com/batkinson/checkers-go/checkers"
+	"github.com/batkinson/checkers-go/checkers/pdn"
+	"github.com/batkinson/checkers-go/checkers/render"
+	"strconv"
+	"strings"
+)
+
+func newGame(client *Client, args ...string) error {
+	if len(args) > 0 && args[0] != "BALLOT" && args[0] != "POSITION" {
+		return errUnsupportedArguments
+	}
+	if client.IsPlayer() {
+		return errAlreadyInGame
+	}
+	var game *Game
+	var err error
+	switch {
+	case len(args) == 0:
+		game = client.server.newGame(checkers.New())
+	case args[0] == "BALLOT":
+		game, err = ballotGame(client.server, args[1:])
+	default:
+		game, err = positionGame(client.server, args[1:])
+	}
+	if err != nil {
+		return err
+	}
+	if err := client.server.lobby.add(game); err != nil {
+		return err
+	}
+	if err := joinGame(client, game.Id); err != nil {
+		abandon(game)
+		return err
+	}
+	return nil
+}
+
+// abandon removes a new game its creator failed to join, so it is not listed
+// forever, unless another player has taken a seat in the meantime.
+func abandon(game *Game) {
+	game.do(func() error {
+		if game.Connected() == 0 {
+			game.remove()
+		}
+		return nil
+	})
+}
+
+func ballotGame(server *Server, args []string) (*Game, error) {
+	if len(args) == 0 {
+		return server.newBallotGame(server.lobby.randomBallot())
+	}
+	ballot, err := checkers.FindBallot(strings.Join(args, " "))
+	if err != nil {
+		return nil, err
+	}
+	return server.newBallotGame(ballot)
+}
+
+// positionGame parses the arguments to NEW POSITION: a board string or FEN,
+// optionally followed by the colour to move, which defaults to black for
+// board strings.
+func positionGame(server *Server, args []string) (*Game, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, errExpectedPosition
+	}
+	var state *checkers.Game
+	var err error
+	if strings.Contains(args[0], ":") {
+		state, err = pdn.ParseFEN(args[0])
+	} else {
+		state, err = checkers.Parse(args[0])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidBoard, err)
+	}
+	if len(args) > 1 {
+		turn, ok := checkers.Players[strings.ToLower(args[1])]
+		if !ok {
+			return nil, errExpectedPosition
+		}
+		state.Turn = turn
+	}
+	return server.newPositionGame(state)
+}
+
+func listGames(client *Client, args ...string) error {
+	spectate := len(args) == 1 && args[0] == "SPECTATE"
+	if !spectate && len(args) > 0 {
+		return errUnsupportedArguments
+	}
+	client.send(client.server.lobby.list(client, spectate))
+	return nil
+}
+
+// enter moves the client into game, leaving any other game first. Joining and
+// spectating are split into a check and an admission that run on the game's
+// goroutine, so the client only leaves its current game once admission is
+// likely to succeed.
+func enter(client *Client, gameId string, check func(*Game) error, admit func(*Game) error) error {
+	game := client.server.lobby.find(gameId)
+	if game == nil {
+		return fmt.Errorf("%w: %v", errNoSuchGame, gameId)
+	}
+	if err := game.do(func() error { return check(game) }); err != nil {
+		return gameError(err, fmt.Errorf("%w: %v", errNoSuchGame, gameId))
+	}
+	if current, _ := client.Membership(); current != nil && current != game {
+		leaveGame(client)
+	}
+	return gameError(game.do(func() error { return admit(game) }), fmt.Errorf("%w: %v", errNoSuchGame, gameId))
+}
+
+// gameError replaces errGameRemoved, for a game removed while a command was
+// on its way to it, with the error the command would otherwise have given.
+func gameError(err, removed error) error {
+	if err == errGameRemoved {
+		return removed
+	}
+	return err
+}
+
+func joinGame(client *Client, args ...string) error {
+	if len(args) != 1 {
+		return errExpectedGameId
+	}
+	var token string
+	var joined *Game
+	err := enter(client, args[0], func(game *Game) error {
+		if game.SeatsFilled() {
+			return errGameFull
+		}
+		return nil
+	}, func(game *Game) error {
+		if game.SeatsFilled() {
+			return errGameFull
+		}
+		if game.IsPlayer(client) || game.IsSpectator(client) {
+			game.leave(client)
+		}
+		assignedPlayer := game.OpenSeats()[0]
+		client.server.Logger.Println("joining", client.Conn.RemoteAddr(), game.Id, assignedPlayer.Color)
+		game.Players[assignedPlayer] = client
+		client.setMembership(game, true)
+		client.send(fmt.Sprintf("STATUS GAME_ID %v", game.Id))
+		game.sendBallot(client)
+		client.send(fmt.Sprintf("STATUS BOARD %v", game.GameState))
+		client.send(fmt.Sprintf("STATUS YOU_ARE %v", assignedPlayer.Color))
+		token, joined = game.startSession(client, assignedPlayer), game
+		game.Broadcast(fmt.Sprintf("STATUS JOINED %v", assignedPlayer.Color), client)
+		game.Broadcast(fmt.Sprintf("STATUS TURN %v", game.Turn()))
+		return nil
+	})
+	if err == nil {
+		client.server.lobby.addSession(token, joined)
+	}
+	return err
+}
+
+func spectateGame(client *Client, args ...string) error {
+	if len(args) != 1 {
+		return errExpectedGameId
+	}
+	canSpectate := func(game *Game) error {
+		if !game.CanSpectate() {
+			return errCannotSpectate
+		}
+		return nil
+	}
+	return enter(client, args[0], canSpectate, func(game *Game) error {
+		if err := canSpectate(game); err != nil {
+			return err
+		}
+		if game.IsPlayer(client) || game.IsSpectator(client) {
+			game.leave(client)
+		}
+		client.server.Logger.Println("spectate", client.Conn.RemoteAddr(), game.Id)
+		game.Spectators = append(game.Spectators, client)
+		client.setMembership(game, false)
+		client.send(fmt.Sprintf("STATUS GAME_ID %v", game.Id))
+		game.sendBallot(client)
+		client.send(fmt.Sprintf("STATUS BOARD %v", game.GameState))
+		client.send(fmt.Sprintf("STATUS TURN %v", game.Turn()))
+		return nil
+	})
+}
+
+func leaveGame(client *Client, args ...string) error {
+	if len(args) > 0 {
+		return errUnsupportedArguments
+	}
+	game, _ := client.Membership()
+	if game == nil {
+		return errNotInGame
+	}
+	return gameError(game.do(func() error { return game.leave(client) }), errNotInGame)
+}
+
+func quit(client *Client, args ...string) error {
+	if len(args) > 0 {
+		return errUnsupportedArguments
+	}
+	if client.IsInGame() {
+		return leaveGame(client)
+	}
+	return nil
+}
+
+func createPos(coords []string) (src, dst checkers.Pos, err error) {
+	if len(coords) != 4 {
+		return checkers.NO_POS, checkers.NO_POS, errInvalidPositions
+	}
+	converted := make([]int, len(coords))
+	for i, val := range coords {
+		parsed, badVal := strconv.ParseInt(val, 0, 0)
+		if badVal != nil {
+			return checkers.NO_POS, checkers.NO_POS, fmt.Errorf("%w: %v", errInvalidPositions, badVal)
+		}
+		converted[i] = int(parsed)
+		i += 1
+	}
+	return checkers.Pos{X: converted[0], Y: converted[1]}, checkers.Pos{X: converted[2], Y: converted[3]}, err
+}
+
+// playing runs fn on the game the client is playing, failing with
+// errNotPlaying if there is none.
+func playing(client *Client, fn func(game *Game) error) error {
+	game, isPlaying := client.Membership()
+	if !isPlaying {
+		return errNotPlaying
+	}
+	return gameError(game.do(func() error {
+		if !game.IsPlayer(client) {
+			return errNotPlaying
+		}
+		return fn(game)
+	}), errNotPlaying)
+}
+
+func move(client *Client, args ...string) error {
+	return playing(client, func(game *Game) error {
+		src, dst, posErr := createPos(args)
+		if posErr != nil {
+			return posErr
+		}
+		if game.HasWinner() {
+			return errGameOver
+		}
+		if !game.TurnIs(client) {
+			return errNotYourTurn
+		}
+		if _, err := game.GameState.Move(src, dst); err != nil {
+			return err
+		}
+		game.Broadcast(fmt.Sprintf("STATUS TURN %v", game.Turn()))
+		return nil
+	})
+}
+
+// prettyOptions parses the options after BOARD PRETTY: COLOR adds ANSI
+// colours and ASCII replaces the Unicode pieces with letters.
+func prettyOptions(args []string) (opts render.TextOptions, err error) {
+	opts = render.DefaultTextOptions()
+	for _, arg := range args {
+		switch arg {
+		case "COLOR":
+			opts.Color = true
+		case "ASCII":
+			opts.Unicode = false
+		default:
+			return opts, errUnsupportedArguments
+		}
+	}
+	return opts, nil
+}
+
+func boardStatus(client *Client, args ...string) error {
+	pretty := len(args) > 0 && args[0] == "PRETTY"
+	if !pretty && len(args) > 0 {
+		return errUnsupportedArguments
+	}
+	return playing(client, func(game *Game) error {
+		if !pretty {
+			client.send(fmt.Sprintf("STATUS BOARD %v", game.GameState))
+			return nil
+		}
+		opts, err := prettyOptions(args[1:])
+		if err != nil {
+			return err
+		}
+		opts.Flip = game.Players[checkers.BLACK_PLAYER] == client
+		opts.LastMove = game.LastMove
+		for _, line := range render.Lines(game.GameState, opts) {
+			client.send(fmt.Sprintf("STATUS PRETTY %v", line))
+		}
+		return nil
+	})
+}
+
+func turnStatus(client *Client, args ...string) error {
+	if len(args) > 0 {
+		return errUnsupportedArguments
+	}
+	return playing(client, func(game *Game) error {
+		client.send(fmt.Sprintf("STATUS TURN %v", game.Turn()))
+		return nil
+	})
+}
+
+type Command func(*Client, ...string) error
+
+var (
+	errInvalidCommand       = errors.New("invalid command")
+	errUnsupportedArguments = errors.New("unsupported arguments")
+	errAlreadyInGame        = errors.New("already in game")
+	errExpectedGameId       = errors.New("expected single game id")
+	errNoSuchGame           = errors.New("game does not exist")
+	errGameFull             = errors.New("game is full")
+	errCannotSpectate       = errors.New("game is not available for spectating")
+	errNotInGame            = errors.New("not in game")
+	errNotPlaying           = errors.New("not playing game")
+	errNotYourTurn          = errors.New("not your turn")
+	errInvalidPositions     = errors.New("invalid positions, expected SRCX SRCY DSTX DSTY")
+	errExpectedPosition     = errors.New("expected board or FEN and optional turn")
+	errInvalidBoard         = errors.New("invalid board")
+	errFinishedPosition     = errors.New("position is already won")
+	errServerFull           = errors.New("too many connections")
+	errExpectedSession      = errors.New("expected single session token")
+	errNoSuchSession        = errors.New("no seat held for session")
+	errGameOver             = errors.New("game is over")
+	errSlowClient           = errors.New("too many unsent messages")
+	errGameRemoved          = errors.New("game removed")
+)
+
+// errorCodes maps the errors a command can fail with to the stable codes sent
+// to clients as ERROR <CODE> <message>.
+var errorCodes = map[error]string{
+	errInvalidCommand:                "INVALID_COMMAND",
+	errUnsupportedArguments:          "UNSUPPORTED_ARGUMENTS",
+	errAlreadyInGame:                 "ALREADY_IN_GAME",
+	errExpectedGameId:                "EXPECTED_GAME_ID",
+	errNoSuchGame:                    "NO_SUCH_GAME",
+	errGameFull:                      "GAME_FULL",
+	errCannotSpectate:                "CANNOT_SPECTATE",
+	errNotInGame:                     "NOT_IN_GAME",
+	errNotPlaying:                    "NOT_PLAYING",
+	errNotYourTurn:                   "NOT_YOUR_TURN",
+	errInvalidPositions:              "INVALID_POSITIONS",
+	errExpectedPosition:              "EXPECTED_POSITION",
+	errInvalidBoard:                  "INVALID_BOARD",
+	errFinishedPosition:              "FINISHED_POSITION",
+	errServerFull:                    "SERVER_FULL",
+	errExpectedSession:               "EXPECTED_SESSION",
+	errNoSuchSession:                 "NO_SUCH_SESSION",
+	errGameOver:                      "GAME_OVER",
+	errSlowClient:                    "SLOW_CLIENT",
+	errExpectedMove:                  "EXPECTED_MOVE",
+	errInvalidRequestBody:            "INVALID_REQUEST_BODY",
+	errMethodNotAllowed:              "METHOD_NOT_ALLOWED",
+	checkers.ErrIllegalPosition:      "ILLEGAL_POSITION",
+	checkers.ErrNoSuchBallot:         "NO_SUCH_BALLOT",
+	checkers.ErrNoPiece:              "NO_PIECE",
+	checkers.ErrOccupied:             "OCCUPIED",
+	checkers.ErrWrongTurn:            "WRONG_TURN",
+	checkers.ErrIllegalDirection:     "ILLEGAL_DIRECTION",
+	checkers.ErrCaptureRequired:      "CAPTURE_REQUIRED",
+	checkers.ErrContinuationRequired: "CONTINUATION_REQUIRED",
+}
+
+const UNKNOWN_ERROR = "INTERNAL"
+
+func errorCode(err error) string {
+	for known, code := range errorCodes {
+		if errors.Is(err, known) {
+			return code
+		}
+	}
+	return UNKNOWN_ERROR
+}
+
+var supportedCommands = map[string]Command{
+	"NEW":      newGame,
+	"LIST":     listGames,
+	"JOIN":     joinGame,
+	"LEAVE":    leaveGame,
+	"MOVE":     move,
+	"BOARD":    boardStatus,
+	"TURN":     turnStatus,
+	"SPECTATE": spectateGame,
+	"QUIT":     quit,
+	"RESUME":   resume,
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Policies for a client whose message queue is full because it is not
+// reading. Sends never wait for a client.
+const (
+	SLOW_DROP       = "drop"
+	SLOW_COALESCE   = "coalesce"
+	SLOW_DISCONNECT = "disconnect"
+)
+
+// Config holds the server's operational settings. Zero limits mean no limit;
+// ClientQueue is a queue size rather than a limit and must be at least one.
+// HTTP is the address ListenAndServeHTTP listens on, and HTTPTimeout bounds
+// how long a request's headers and body may take to arrive.
+type Config struct {
+	Listen        string
+	HTTP          string
+	ServerQueue   int
+	ClientQueue   int
+	GameIdLength  int
+	MaxSpectators int
+	MaxClients    int
+	IdleTimeout   time.Duration
+	ResumeGrace   time.Duration
+	SlowClients   string
+	WriteTimeout  time.Duration
+	HTTPTimeout   time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Listen:        ":5000",
+		ServerQueue:   4096,
+		ClientQueue:   16,
+		GameIdLength:  16,
+		MaxSpectators: 8,
+		ResumeGrace:   time.Minute,
+		SlowClients:   SLOW_DISCONNECT,
+		WriteTimeout:  30 * time.Second,
+		HTTPTimeout:   10 * time.Second,
+	}
+}
+
+func (cfg Config) Validate() error {
+	switch {
+	case cfg.ServerQueue < 0:
+		return errors.New("invalid config, queue sizes may not be negative")
+	case cfg.ClientQueue < 1:
+		return errors.New("invalid config, clients need room to queue at least one message")
+	case cfg.GameIdLength < 1:
+		return errors.New("invalid config, game ids need at least one character")
+	case cfg.MaxSpectators < 0, cfg.MaxClients < 0, cfg.IdleTimeout < 0, cfg.ResumeGrace < 0, cfg.WriteTimeout < 0, cfg.HTTPTimeout < 0:
+		return errors.New("invalid config, limits may not be negative")
+	case cfg.SlowClients != SLOW_DROP && cfg.SlowClients != SLOW_COALESCE && cfg.SlowClients != SLOW_DISCONNECT:
+		return fmt.Errorf("invalid config, unknown slow client policy: %v", cfg.SlowClients)
+	}
+	return nil
+}
@@ -0,0 +1,312 @@
+package server
+
+import (
+	"fmt"
+	"github.com/batkinson/checkers-go/checkers"
+	"sync/atomic"
+)
+
+// Game is an actor: it runs on its own goroutine, and everything but its Id
+// and Ballot is only touched by functions passed to do. Games are independent
+// of each other, so they proceed in parallel.
+type Game struct {
+	server     *Server
+	Id         string
+	Players    map[checkers.Player]*Client
+	Spectators []*Client
+	GameState  *checkers.Game
+	Ballot     checkers.Ballot
+	LastMove   checkers.Move
+	Sessions   map[checkers.Player]*Session
+	Forfeited  checkers.Player
+	inbox      chan func()
+	removed    chan bool
+	finished   bool
+	listing    atomic.Value
+}
+
+// Listing is what the lobby knows of a game, published after every change so
+// LIST does not have to wait for each game.
+type Listing struct {
+	NeedsPlayer bool
+	CanSpectate bool
+}
+
+func (game *Game) run() {
+	for {
+		select {
+		case task := <-game.inbox:
+			task()
+			game.listing.Store(Listing{game.NeedsPlayer(), game.CanSpectate()})
+		case <-game.removed:
+			return
+		}
+	}
+}
+
+// do runs fn on the game's goroutine and returns its result, or
+// errGameRemoved if the game has been removed.
+func (game *Game) do(fn func() error) error {
+	done := make(chan error, 1)
+	task := func() {
+		if game.finished {
+			done <- errGameRemoved
+			return
+		}
+		done <- fn()
+	}
+	select {
+	case game.inbox <- task:
+		return <-done
+	case <-game.removed:
+		return errGameRemoved
+	}
+}
+
+func (game *Game) Listing() Listing {
+	return game.listing.Load().(Listing)
+}
+
+func (game *Game) SeatsFilled() bool {
+	return len(game.OpenSeats()) == 0
+}
+
+// OpenSeats lists the seats not yet taken, black's first.
+func (game *Game) OpenSeats() []checkers.Player {
+	openSeats := []checkers.Player{}
+	for _, player := range []checkers.Player{checkers.BLACK_PLAYER, checkers.RED_PLAYER} {
+		if _, seatFilled := game.Players[player]; !seatFilled {
+			openSeats = append(openSeats, player)
+		}
+	}
+	return openSeats
+}
+
+func (game *Game) TurnIs(client *Client) bool {
+	for p, c := range game.Players {
+		if c == client && game.GameState.TurnIs(p) {
+			return true
+		}
+	}
+	return false
+}
+
+func (game *Game) HasWinner() bool {
+	return game.Forfeited != checkers.NO_PLAYER || game.GameState.Winner() != checkers.NO_PLAYER
+}
+
+func (game *Game) Winner() string {
+	if game.Forfeited != checkers.NO_PLAYER {
+		return checkers.Opponents[game.Forfeited].Color
+	}
+	return game.GameState.Winner().Color
+}
+
+// Connected counts the players in the game, excluding seats held for players
+// who may resume.
+func (game *Game) Connected() int {
+	connected := 0
+	for _, client := range game.Players {
+		if client != nil {
+			connected++
+		}
+	}
+	return connected
+}
+
+func (game *Game) Broadcast(message string, excluded ...*Client) {
+	isExcluded := make(map[*Client]bool)
+	for _, client := range excluded {
+		isExcluded[client] = true
+	}
+	for _, player := range game.Players {
+		if player != nil && !isExcluded[player] {
+			player.send(message)
+		}
+	}
+	for _, spectator := range game.Spectators {
+		if !isExcluded[spectator] {
+			spectator.send(message)
+		}
+	}
+}
+
+// newGame creates a game in the given position. It has no id and does not run
+// until added to the lobby.
+func (server *Server) newGame(state *checkers.Game) *Game {
+	game := &Game{
+		server:     server,
+		Players:    make(map[checkers.Player]*Client),
+		Spectators: make([]*Client, 0, server.Config.MaxSpectators),
+		GameState:  state,
+		Ballot:     checkers.NO_BALLOT,
+		LastMove:   checkers.NO_MOVE,
+		Sessions:   make(map[checkers.Player]*Session),
+		Forfeited:  checkers.NO_PLAYER,
+		inbox:      make(chan func()),
+		removed:    make(chan bool),
+	}
+	state.AddListener(game.announce)
+	game.listing.Store(Listing{game.NeedsPlayer(), game.CanSpectate()})
+	return game
+}
+
+func (server *Server) newBallotGame(ballot checkers.Ballot) (*Game, error) {
+	state, err := checkers.NewBallot(ballot)
+	if err != nil {
+		return nil, err
+	}
+	game := server.newGame(state)
+	game.Ballot = ballot
+	return game, nil
+}
+
+// newPositionGame starts a game from a custom position, which must be legal
+// and not already decided. A side to move with no legal move has lost, so
+// such a position could never be played out.
+func (server *Server) newPositionGame(state *checkers.Game) (*Game, error) {
+	if findings := checkers.Validate(state); len(findings) > 0 {
+		return nil, &checkers.ValidationError{Findings: findings}
+	}
+	if state.Winner() != checkers.NO_PLAYER {
+		return nil, errFinishedPosition
+	}
+	if len(state.LegalMoves()) == 0 {
+		return nil, fmt.Errorf("%w: %v has no legal move", errFinishedPosition, state.Turn.Color)
+	}
+	return server.newGame(state), nil
+}
+
+// announce broadcasts the changes each move makes. The turn is sent by the
+// caller after every move, whether or not it changed.
+func (game *Game) announce(event checkers.Event) {
+	switch event.Type {
+	case checkers.PIECE_MOVED:
+		game.LastMove = checkers.Move{Src: event.Src, Dst: event.Dst}
+		game.Broadcast(fmt.Sprintf("STATUS MOVED %v %v %v %v", event.Src.X, event.Src.Y, event.Dst.X, event.Dst.Y))
+	case checkers.PIECE_CAPTURED:
+		game.Broadcast(fmt.Sprintf("STATUS CAPTURED %v %v", event.Pos.X, event.Pos.Y))
+	case checkers.PIECE_CROWNED:
+		game.Broadcast(fmt.Sprintf("STATUS KING %v %v", event.Pos.X, event.Pos.Y))
+	case checkers.GAME_FINISHED:
+		game.Broadcast(fmt.Sprintf("STATUS WINNER %v", event.Player.Color))
+	}
+}
+
+func (game *Game) sendBallot(client *Client) {
+	if game.Ballot != checkers.NO_BALLOT {
+		client.send(fmt.Sprintf("STATUS BALLOT %v %v", game.Ballot.Number, game.Ballot))
+	}
+}
+
+func (game *Game) NeedsPlayer() bool {
+	return !game.SeatsFilled() && !game.HasWinner()
+}
+
+func (game *Game) CanSpectate() bool {
+	limit := game.server.Config.MaxSpectators
+	return game.SeatsFilled() && !game.HasWinner() && (limit == 0 || len(game.Spectators) < limit)
+}
+
+func (game *Game) IsSpectator(client *Client) bool {
+	for _, spectator := range game.Spectators {
+		if spectator == client {
+			return true
+		}
+	}
+	return false
+}
+
+func (game *Game) IsPlayer(client *Client) bool {
+	for _, player := range game.Players {
+		if player == client {
+			return true
+		}
+	}
+	return false
+}
+
+func (game *Game) Turn() string {
+	if game.SeatsFilled() {
+		return game.GameState.Turn.Color
+	}
+	return "waiting"
+}
+
+func (client *Client) IsSpectator() bool {
+	game, playing := client.Membership()
+	return game != nil && !playing
+}
+
+func (client *Client) IsPlayer() bool {
+	_, playing := client.Membership()
+	return playing
+}
+
+func (client *Client) IsInGame() bool {
+	game, _ := client.Membership()
+	return game != nil
+}
+
+// leave removes a player or spectator from the game, removing the game once
+// no players remain.
+func (game *Game) leave(client *Client) error {
+	for p, c := range game.Players {
+		if c == client {
+			game.server.Logger.Println("leaving", client.Conn.RemoteAddr(), game.Id)
+			client.setMembership(nil, false)
+			delete(game.Players, p)
+			game.endSession(p)
+			game.Broadcast(fmt.Sprintf("STATUS LEFT %v", p.Color))
+			game.Broadcast(fmt.Sprintf("STATUS TURN %v", game.Turn()))
+			if game.Connected() == 0 {
+				game.remove()
+			}
+			return nil
+		}
+	}
+	for i, c := range game.Spectators {
+		if c == client {
+			game.server.Logger.Println("leaving", client.Conn.RemoteAddr(), game.Id)
+			client.setMembership(nil, false)
+			finalIndex := len(game.Spectators) - 1
+			game.Spectators[i] = game.Spectators[finalIndex]
+			game.Spectators[finalIndex] = c
+			game.Spectators = game.Spectators[:finalIndex]
+			return nil
+		}
+	}
+	return errNotInGame
+}
+
+// remove stops a game nobody is playing, detaching any spectators.
+func (game *Game) remove() {
+	game.server.Logger.Println("removing", game.Id)
+	for player, session := range game.Sessions {
+		session.stopTimer()
+		delete(game.Sessions, player)
+	}
+	for _, spectator := range game.Spectators {
+		spectator.setMembership(nil, false)
+	}
+	game.Spectators = game.Spectators[:0]
+	game.finished = true
+	close(game.removed)
+	game.server.lobby.remove(game)
+}
+
+// disconnect runs once a client's connection has ended. Players of a game
+// under way keep their seat for the resume grace period; everyone else leaves
+// as LEAVE would. The client is then released.
+func disconnect(client *Client) {
+	if game, _ := client.Membership(); game != nil {
+		game.do(func() error {
+			if game.IsPlayer(client) && game.CanHold() {
+				game.hold(client)
+				return nil
+			}
+			return game.leave(client)
+		})
+	}
+	client.Close()
+}
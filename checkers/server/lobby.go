@@ -0,0 +1,188 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/batkinson/checkers-go/checkers"
+	"sync"
+)
+
+// Lobby is the registry of games and held sessions. Like each game, it runs
+// on its own goroutine and its state is only touched by functions passed to
+// do.
+type Lobby struct {
+	server   *Server
+	inbox    chan func()
+	stopped  chan bool
+	stop     sync.Once
+	games    map[string]*Game
+	sessions map[string]*Game
+}
+
+func newLobby(server *Server) *Lobby {
+	lobby := &Lobby{
+		server:   server,
+		inbox:    make(chan func(), server.Config.ServerQueue),
+		stopped:  make(chan bool),
+		games:    make(map[string]*Game),
+		sessions: make(map[string]*Game),
+	}
+	go lobby.run()
+	return lobby
+}
+
+func (lobby *Lobby) run() {
+	for {
+		select {
+		case task := <-lobby.inbox:
+			task()
+		case <-lobby.stopped:
+			return
+		}
+	}
+}
+
+// do runs fn on the lobby's goroutine and returns its result, or
+// ErrServerClosed once the lobby has stopped.
+func (lobby *Lobby) do(fn func() error) error {
+	done := make(chan error, 1)
+	task := func() {
+		done <- fn()
+	}
+	select {
+	case lobby.inbox <- task:
+	case <-lobby.stopped:
+		return ErrServerClosed
+	}
+	select {
+	case err := <-done:
+		return err
+	case <-lobby.stopped:
+		return ErrServerClosed
+	}
+}
+
+var letters = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
+
+func (lobby *Lobby) generateGameId() string {
+	b := make([]rune, lobby.server.Config.GameIdLength)
+	for i := range b {
+		b[i] = letters[lobby.server.Rand.Intn(len(letters))]
+	}
+	return string(b)
+}
+
+// add gives a new game an unused id, registers it and starts it.
+func (lobby *Lobby) add(game *Game) error {
+	err := lobby.do(func() error {
+		game.Id = lobby.generateGameId()
+		for lobby.games[game.Id] != nil {
+			game.Id = lobby.generateGameId()
+		}
+		lobby.games[game.Id] = game
+		return nil
+	})
+	if err == nil {
+		go game.run()
+	}
+	return err
+}
+
+func (lobby *Lobby) find(gameId string) (game *Game) {
+	lobby.do(func() error {
+		game = lobby.games[gameId]
+		return nil
+	})
+	return game
+}
+
+// remove forgets a game and every session indexed to it. Games call it once
+// they are over, without waiting, so a game never blocks on the lobby.
+func (lobby *Lobby) remove(game *Game) {
+	go lobby.do(func() error {
+		delete(lobby.games, game.Id)
+		for token, indexed := range lobby.sessions {
+			if indexed == game {
+				delete(lobby.sessions, token)
+			}
+		}
+		return nil
+	})
+}
+
+// addSession indexes a token to its game, unless the game has already been
+// removed.
+func (lobby *Lobby) addSession(token string, game *Game) {
+	lobby.do(func() error {
+		if lobby.games[game.Id] == game {
+			lobby.sessions[token] = game
+		}
+		return nil
+	})
+}
+
+func (lobby *Lobby) endSession(token string) {
+	go lobby.do(func() error {
+		delete(lobby.sessions, token)
+		return nil
+	})
+}
+
+func (lobby *Lobby) findSession(token string) (game *Game) {
+	lobby.do(func() error {
+		game = lobby.sessions[token]
+		return nil
+	})
+	return game
+}
+
+func (lobby *Lobby) randomBallot() (ballot checkers.Ballot) {
+	lobby.do(func() error {
+		ballot = checkers.RandomBallot(lobby.server.Rand)
+		return nil
+	})
+	return ballot
+}
+
+// list returns the ids of games the client could join or, with spectate set,
+// watch. It relies on the listing each game publishes after every change.
+func (lobby *Lobby) list(client *Client, spectate bool) string {
+	current, playing := client.Membership()
+	var gameIds bytes.Buffer
+	lobby.do(func() error {
+		for gameId, game := range lobby.games {
+			listing := game.Listing()
+			if (spectate && listing.CanSpectate && (game != current || playing)) || (!spectate && game != current && listing.NeedsPlayer) {
+				if gameIds.Len() > 0 {
+					gameIds.WriteString(" ")
+				}
+				gameIds.WriteString(gameId)
+			}
+		}
+		return nil
+	})
+	if spectate {
+		return fmt.Sprintf("STATUS LIST SPECTATE %v", gameIds.String())
+	}
+	return fmt.Sprintf("STATUS LIST %v", gameIds.String())
+}
+
+// shutdown removes every game, then stops the lobby.
+func (lobby *Lobby) shutdown() {
+	games := []*Game{}
+	lobby.do(func() error {
+		for _, game := range lobby.games {
+			games = append(games, game)
+		}
+		return nil
+	})
+	for _, game := range games {
+		game.do(func() error {
+			game.remove()
+			return nil
+		})
+	}
+	lobby.stop.Do(func() {
+		close(lobby.stopped)
+	})
+}
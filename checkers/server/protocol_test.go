@@ -0,0 +1,282 @@
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/batkinson/checkers-go/checkers"
+	"io"
+	"math/rand"
+	"net"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// WIN_POSITION leaves black one capture and red a reply that takes black's
+// last piece.
+const WIN_POSITION = "********|********|********|********|*b******|**r*****|********|****r***"
+
+var sessionLine = regexp.MustCompile(`^STATUS SESSION [0-9a-f]{32}$`)
+
+// listen serves a quiet, deterministic server on a loopback port for one
+// test and returns its address.
+func listen(t *testing.T) string {
+	server := startServer(t, SLOW_DISCONNECT)
+	server.Rand = rand.New(rand.NewSource(1))
+	server.Entropy = rand.New(rand.NewSource(2))
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(listener)
+	return listener.Addr().String()
+}
+
+// wire is a real TCP client that checks every line it receives.
+type wire struct {
+	t     *testing.T
+	conn  net.Conn
+	lines *bufio.Reader
+}
+
+func dialWire(t *testing.T, addr string) *wire {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &wire{t, conn, bufio.NewReader(conn)}
+}
+
+func (w *wire) send(line string) {
+	fmt.Fprintf(w.conn, "%v\r\n", line)
+}
+
+func (w *wire) next() (string, error) {
+	w.conn.SetReadDeadline(time.Now().Add(TEST_TIMEOUT))
+	line, err := w.lines.ReadString('\n')
+	return strings.TrimSuffix(line, "\r\n"), err
+}
+
+// expect checks that the next lines received are exactly lines, in order.
+func (w *wire) expect(lines ...string) {
+	w.t.Helper()
+	for _, expected := range lines {
+		line, err := w.next()
+		if err != nil {
+			w.t.Fatalf("expected %q, got %v", expected, err)
+		}
+		if line != expected {
+			w.t.Fatalf("expected %q, got %q", expected, line)
+		}
+	}
+}
+
+// expectMatch checks the next line against a pattern and returns it.
+func (w *wire) expectMatch(pattern *regexp.Regexp) string {
+	w.t.Helper()
+	line, err := w.next()
+	if err != nil {
+		w.t.Fatalf("expected %v, got %v", pattern, err)
+	}
+	if !pattern.MatchString(line) {
+		w.t.Fatalf("expected %v, got %q", pattern, line)
+	}
+	return line
+}
+
+// expectClosed checks that the server closes the connection without sending
+// anything more.
+func (w *wire) expectClosed() {
+	w.t.Helper()
+	if line, err := w.next(); err != io.EOF {
+		w.t.Fatalf("expected connection to close, got %q, %v", line, err)
+	}
+}
+
+// create starts a game with NEW and the given arguments, returning its id and
+// the colour assigned, which is black as the first open seat.
+func (w *wire) create(args string, board string) (string, string) {
+	w.t.Helper()
+	w.send(strings.TrimSpace("NEW " + args))
+	id := strings.TrimPrefix(w.expectMatch(regexp.MustCompile(`^STATUS GAME_ID [a-zA-Z]{16}$`)), "STATUS GAME_ID ")
+	w.expect("STATUS BOARD " + board)
+	w.expect("STATUS YOU_ARE " + checkers.BLACK)
+	color := checkers.BLACK
+	w.expectMatch(sessionLine)
+	w.expect("STATUS TURN waiting", "OK")
+	return id, color
+}
+
+// join takes the seat left in game id after its creator took creator's.
+func (w *wire) join(id, creator, board string) string {
+	w.t.Helper()
+	color := checkers.Opponents[checkers.Players[creator]].Color
+	w.send("JOIN " + id)
+	w.expect("STATUS GAME_ID "+id, "STATUS BOARD "+board, "STATUS YOU_ARE "+color)
+	w.expectMatch(sessionLine)
+	w.expect("STATUS TURN black", "OK")
+	return color
+}
+
+func TestProtocolLobby(t *testing.T) {
+	a := dialWire(t, listen(t))
+	for _, exchange := range [][]string{
+		{"LIST", "STATUS LIST ", "OK"},
+		{"LIST SPECTATE", "STATUS LIST SPECTATE ", "OK"},
+		{"LIST ALL", "ERROR UNSUPPORTED_ARGUMENTS unsupported arguments"},
+		{"DANCE", "ERROR INVALID_COMMAND invalid command"},
+		{"NEW GAME", "ERROR UNSUPPORTED_ARGUMENTS unsupported arguments"},
+		{"JOIN", "ERROR EXPECTED_GAME_ID expected single game id"},
+		{"JOIN nonsense", "ERROR NO_SUCH_GAME game does not exist: nonsense"},
+		{"SPECTATE nonsense", "ERROR NO_SUCH_GAME game does not exist: nonsense"},
+		{"LEAVE", "ERROR NOT_IN_GAME not in game"},
+		{"MOVE 1 2 0 3", "ERROR NOT_PLAYING not playing game"},
+		{"BOARD", "ERROR NOT_PLAYING not playing game"},
+		{"TURN", "ERROR NOT_PLAYING not playing game"},
+		{"QUIT", "OK"},
+	} {
+		a.send(exchange[0])
+		a.expect(exchange[1:]...)
+	}
+	a.expectClosed()
+}
+
+func TestProtocolJoinAndLeave(t *testing.T) {
+	addr := listen(t)
+	a, b, c := dialWire(t, addr), dialWire(t, addr), dialWire(t, addr)
+	board := checkers.New().String()
+	id, aColor := a.create("", board)
+	b.send("LIST")
+	b.expect("STATUS LIST "+id, "OK")
+	b.send("SPECTATE " + id)
+	b.expect("ERROR CANNOT_SPECTATE game is not available for spectating")
+	bColor := b.join(id, aColor, board)
+	a.expect("STATUS JOINED "+bColor, "STATUS TURN black")
+	a.send("NEW")
+	a.expect("ERROR ALREADY_IN_GAME already in game")
+	c.send("JOIN " + id)
+	c.expect("ERROR GAME_FULL game is full")
+	c.send("LIST")
+	c.expect("STATUS LIST ", "OK")
+	c.send("LIST SPECTATE")
+	c.expect("STATUS LIST SPECTATE "+id, "OK")
+	b.send("LEAVE")
+	b.expect("OK")
+	a.expect("STATUS LEFT "+bColor, "STATUS TURN waiting")
+	b.send("LEAVE")
+	b.expect("ERROR NOT_IN_GAME not in game")
+	b.send("LIST")
+	b.expect("STATUS LIST "+id, "OK")
+	a.send("QUIT")
+	a.expect("OK")
+	a.expectClosed()
+	b.send("LIST")
+	b.expect("STATUS LIST ", "OK")
+	b.send("JOIN " + id)
+	b.expect("ERROR NO_SUCH_GAME game does not exist: " + id)
+}
+
+func TestProtocolMoves(t *testing.T) {
+	addr := listen(t)
+	a, b := dialWire(t, addr), dialWire(t, addr)
+	game := checkers.New()
+	id, aColor := a.create("", game.String())
+	bColor := b.join(id, aColor, game.String())
+	a.expect("STATUS JOINED "+bColor, "STATUS TURN black")
+	players := map[string]*wire{aColor: a, bColor: b}
+	black, red := players[checkers.BLACK], players[checkers.RED]
+	red.send("MOVE 2 5 3 4")
+	red.expect("ERROR NOT_YOUR_TURN not your turn")
+	black.send("MOVE 1 2 1 3")
+	black.expect("ERROR ILLEGAL_DIRECTION invalid move: {1 2} to {1 3}")
+	black.send("MOVE 1 2")
+	black.expect("ERROR INVALID_POSITIONS invalid positions, expected SRCX SRCY DSTX DSTY")
+	black.send("MOVE 1 2 2 3")
+	for _, player := range []*wire{black, red} {
+		player.expect("STATUS MOVED 1 2 2 3", "STATUS TURN red")
+	}
+	black.expect("OK")
+	game.Move(checkers.Pos{X: 1, Y: 2}, checkers.Pos{X: 2, Y: 3})
+	red.send("TURN")
+	red.expect("STATUS TURN red", "OK")
+	red.send("BOARD")
+	red.expect("STATUS BOARD "+game.String(), "OK")
+	red.send("BOARD UPSIDE")
+	red.expect("ERROR UNSUPPORTED_ARGUMENTS unsupported arguments")
+	red.send("MOVE 0 5 1 4")
+	for _, player := range []*wire{black, red} {
+		player.expect("STATUS MOVED 0 5 1 4", "STATUS TURN black")
+	}
+	red.expect("OK")
+	black.send("MOVE 3 2 4 3")
+	black.expect("ERROR CAPTURE_REQUIRED capture required: {3 2} to {4 3}")
+	black.send("QUIT")
+	black.expect("OK")
+	black.expectClosed()
+	red.expect("STATUS LEFT black", "STATUS TURN waiting")
+}
+
+func TestProtocolUnplayablePosition(t *testing.T) {
+	w := dialWire(t, listen(t))
+	blocked := "********|********|********|********|*b******|r*r*****|***r****|********"
+	w.send("NEW POSITION " + blocked)
+	w.expect("ERROR FINISHED_POSITION position is already won: black has no legal move")
+	w.create("POSITION "+blocked+" red", blocked)
+}
+
+func TestProtocolSpectatedWin(t *testing.T) {
+	addr := listen(t)
+	a, b := dialWire(t, addr), dialWire(t, addr)
+	spectators := []*wire{dialWire(t, addr), dialWire(t, addr)}
+	board := WIN_POSITION
+	id, aColor := a.create("POSITION "+WIN_POSITION, board)
+	bColor := b.join(id, aColor, board)
+	a.expect("STATUS JOINED "+bColor, "STATUS TURN black")
+	for _, spectator := range spectators {
+		spectator.send("SPECTATE " + id)
+		spectator.expect("STATUS GAME_ID "+id, "STATUS BOARD "+board, "STATUS TURN black", "OK")
+	}
+	spectators[0].send("LIST SPECTATE")
+	spectators[0].expect("STATUS LIST SPECTATE ", "OK")
+	a.send("LIST SPECTATE")
+	a.expect("STATUS LIST SPECTATE "+id, "OK")
+	spectators[0].send("MOVE 1 4 3 6")
+	spectators[0].expect("ERROR NOT_PLAYING not playing game")
+	players := map[string]*wire{aColor: a, bColor: b}
+	black, red := players[checkers.BLACK], players[checkers.RED]
+	everyone := append([]*wire{black, red}, spectators...)
+	black.send("MOVE 1 4 3 6")
+	for _, w := range everyone {
+		w.expect("STATUS MOVED 1 4 3 6", "STATUS CAPTURED 2 5", "STATUS TURN red")
+	}
+	black.expect("OK")
+	red.send("MOVE 4 7 2 5")
+	for _, w := range everyone {
+		w.expect("STATUS MOVED 4 7 2 5", "STATUS CAPTURED 3 6", "STATUS WINNER red", "STATUS TURN red")
+	}
+	red.expect("OK")
+	red.send("MOVE 2 5 1 4")
+	red.expect("ERROR GAME_OVER game is over")
+	spectators[1].send("LIST SPECTATE")
+	spectators[1].expect("STATUS LIST SPECTATE ", "OK")
+	spectators[1].send("LEAVE")
+	spectators[1].expect("OK")
+	black.send("LEAVE")
+	black.expect("OK")
+	for _, w := range []*wire{red, spectators[0]} {
+		w.expect("STATUS LEFT black", "STATUS TURN waiting")
+	}
+	red.send("QUIT")
+	red.expect("OK")
+	red.expectClosed()
+	spectators[0].expect("STATUS LEFT red", "STATUS TURN waiting")
+	spectators[0].send("LEAVE")
+	spectators[0].expect("ERROR NOT_IN_GAME not in game")
+	for _, w := range append([]*wire{black}, spectators...) {
+		w.send("QUIT")
+		w.expect("OK")
+		w.expectClosed()
+	}
+}
@@ -0,0 +1,261 @@
+// Package server implements the checkers line protocol. A Server can be
+// embedded in other programs, serving any number of listeners or individual
+// connections; checkers-server is a thin wrapper around one.
+package server
+
+import (
+	"context"
+	crand "crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// ACCEPT_RETRY_DELAY is how long Serve waits after a failed Accept before
+// trying again.
+const ACCEPT_RETRY_DELAY = 10 * time.Millisecond
+
+var ErrServerClosed = errors.New("server closed")
+
+// Clock tells the time for the server's game timing, such as the resume grace
+// period. Network deadlines always use the system clock.
+type Clock interface {
+	Now() time.Time
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+type Timer interface {
+	Stop() bool
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+var SystemClock Clock = systemClock{}
+
+// Server runs the checkers protocol for every connection given to it, sharing
+// one lobby of games between them. Create servers with New; the exported
+// fields may be replaced before the first connection is served.
+type Server struct {
+	Config Config
+	Clock  Clock
+	// Rand generates game ids and draws ballots. It is only used from the
+	// lobby's goroutine.
+	Rand *rand.Rand
+	// Entropy provides session tokens, which must be unguessable.
+	Entropy io.Reader
+	Logger  *log.Logger
+
+	start     sync.Once
+	lobby     *Lobby
+	mux       *http.ServeMux
+	slots     chan bool
+	entropy   sync.Mutex
+	lock      sync.Mutex
+	closing   bool
+	listeners map[net.Listener]bool
+	clients   map[*Client]bool
+	active    sync.WaitGroup
+	// httpPlayers are the seats taken over HTTP, by session token.
+	httpPlayers map[string]*httpPlayer
+}
+
+func New(config Config) *Server {
+	return &Server{
+		Config:      config,
+		Clock:       SystemClock,
+		Rand:        rand.New(rand.NewSource(time.Now().UnixNano())),
+		Entropy:     crand.Reader,
+		Logger:      log.New(os.Stdout, "", 0),
+		listeners:   make(map[net.Listener]bool),
+		clients:     make(map[*Client]bool),
+		httpPlayers: make(map[string]*httpPlayer),
+	}
+}
+
+func (server *Server) init() {
+	server.start.Do(func() {
+		server.lobby = newLobby(server)
+		server.mux = http.NewServeMux()
+		server.mux.HandleFunc(WEBSOCKET_PATH, server.ServeWebSocket)
+		server.mux.HandleFunc(API_PATH, server.serveAPI)
+		server.mux.HandleFunc(API_PATH+"/", server.serveAPI)
+		if server.Config.MaxClients > 0 {
+			server.slots = make(chan bool, server.Config.MaxClients)
+		}
+	})
+}
+
+// ListenAndServe serves TCP connections on Config.Listen.
+func (server *Server) ListenAndServe() error {
+	listener, err := net.Listen("tcp", server.Config.Listen)
+	if err != nil {
+		return err
+	}
+	return server.Serve(listener)
+}
+
+// track registers a listener for Shutdown to close, returning false and
+// closing it if the server is already shutting down.
+func (server *Server) track(listener net.Listener) bool {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+	if server.closing {
+		listener.Close()
+		return false
+	}
+	server.listeners[listener] = true
+	return true
+}
+
+func (server *Server) untrack(listener net.Listener) {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+	delete(server.listeners, listener)
+}
+
+// Serve accepts connections from listener, serving each on its own goroutine,
+// until Shutdown closes it. It always returns an error, ErrServerClosed after
+// Shutdown.
+func (server *Server) Serve(listener net.Listener) error {
+	server.init()
+	if !server.track(listener) {
+		return ErrServerClosed
+	}
+	defer server.untrack(listener)
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if server.isClosing() {
+				return ErrServerClosed
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return err
+			}
+			server.Logger.Println(err)
+			time.Sleep(ACCEPT_RETRY_DELAY)
+			continue
+		}
+		go server.ServeConn(conn)
+	}
+}
+
+// ListenAndServeHTTP serves HTTP on Config.HTTP.
+func (server *Server) ListenAndServeHTTP() error {
+	listener, err := net.Listen("tcp", server.Config.HTTP)
+	if err != nil {
+		return err
+	}
+	return server.ServeHTTPListener(listener)
+}
+
+// ServeHTTPListener serves HTTP requests from listener with ServeHTTP until
+// Shutdown closes it, closing connections whose requests take longer than
+// Config.HTTPTimeout to arrive. It always returns an error, ErrServerClosed after
+// Shutdown.
+func (server *Server) ServeHTTPListener(listener net.Listener) error {
+	server.init()
+	if !server.track(listener) {
+		return ErrServerClosed
+	}
+	defer server.untrack(listener)
+	timeout := server.Config.HTTPTimeout
+	httpServer := &http.Server{Handler: server, ReadHeaderTimeout: timeout, ReadTimeout: timeout}
+	err := httpServer.Serve(listener)
+	if server.isClosing() {
+		return ErrServerClosed
+	}
+	return err
+}
+
+// ServeHTTP answers WebSocket connections at WEBSOCKET_PATH and the JSON API
+// at API_PATH, so the server can be mounted in another program's HTTP server.
+func (server *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	server.init()
+	server.mux.ServeHTTP(w, r)
+}
+
+// ServeConn runs the protocol on one connection until it ends. Connections
+// beyond Config.MaxClients are refused with SERVER_FULL.
+func (server *Server) ServeConn(conn net.Conn) {
+	server.init()
+	if server.slots != nil {
+		select {
+		case server.slots <- true:
+			defer func() { <-server.slots }()
+		default:
+			fmt.Fprintf(conn, "ERROR %v %v\r\n", errorCode(errServerFull), errServerFull)
+			conn.Close()
+			return
+		}
+	}
+	client := server.newClient(conn)
+	server.lock.Lock()
+	if server.closing {
+		server.lock.Unlock()
+		conn.Close()
+		return
+	}
+	server.clients[client] = true
+	server.active.Add(1)
+	server.lock.Unlock()
+	defer func() {
+		server.lock.Lock()
+		delete(server.clients, client)
+		server.lock.Unlock()
+		server.active.Done()
+	}()
+	server.Logger.Println("connect", conn.RemoteAddr())
+	client.serve()
+}
+
+// Shutdown stops accepting connections, closes those already open and waits
+// for them to finish before removing every game. If ctx ends first, the games
+// are removed anyway, Shutdown returns ctx's error and the remaining
+// connections finish on their own.
+func (server *Server) Shutdown(ctx context.Context) error {
+	server.init()
+	server.lock.Lock()
+	server.closing = true
+	for listener := range server.listeners {
+		listener.Close()
+	}
+	for client := range server.clients {
+		client.Conn.Close()
+	}
+	server.lock.Unlock()
+	server.dropHTTPPlayers()
+	done := make(chan bool)
+	go func() {
+		server.active.Wait()
+		close(done)
+	}()
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	server.lobby.shutdown()
+	return err
+}
+
+func (server *Server) isClosing() bool {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+	return server.closing
+}
@@ -0,0 +1,409 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"github.com/batkinson/checkers-go/checkers"
+	"io/ioutil"
+	"log"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	TEST_TIMEOUT = 2 * time.Second
+	TEST_GRACE   = 100 * time.Millisecond
+)
+
+// startServer starts a quiet server for one test, with a short resume grace
+// period and the given slow client policy. It is shut down after the test.
+func startServer(t *testing.T, policy string) *Server {
+	config := DefaultConfig()
+	config.ResumeGrace = TEST_GRACE
+	config.SlowClients = policy
+	server := New(config)
+	server.Logger = log.New(ioutil.Discard, "", 0)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), TEST_TIMEOUT)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			t.Errorf("expected server to shut down: %v", err)
+		}
+	})
+	return server
+}
+
+type testClient struct {
+	t     *testing.T
+	conn  net.Conn
+	lines chan string
+}
+
+// dial connects a client that reads nothing until startReading is called.
+func dial(t *testing.T, server *Server) *testClient {
+	serverConn, conn := net.Pipe()
+	go server.ServeConn(serverConn)
+	return &testClient{t, conn, make(chan string, 100)}
+}
+
+func connect(t *testing.T, server *Server) *testClient {
+	client := dial(t, server)
+	client.startReading()
+	return client
+}
+
+func (client *testClient) startReading() {
+	go func() {
+		reader := bufio.NewReader(client.conn)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				close(client.lines)
+				return
+			}
+			client.lines <- strings.TrimRight(line, "\r\n")
+		}
+	}()
+}
+
+func (client *testClient) send(line string) {
+	fmt.Fprintf(client.conn, "%v\r\n", line)
+}
+
+// expect skips lines until one starts with prefix and returns it.
+func (client *testClient) expect(prefix string) string {
+	client.t.Helper()
+	timeout := time.After(TEST_TIMEOUT)
+	for {
+		select {
+		case line, ok := <-client.lines:
+			if !ok {
+				client.t.Fatalf("connection closed waiting for %q", prefix)
+			}
+			if strings.HasPrefix(line, prefix) {
+				return line
+			}
+		case <-timeout:
+			client.t.Fatalf("timed out waiting for %q", prefix)
+		}
+	}
+}
+
+func (client *testClient) expectClosed() {
+	client.t.Helper()
+	timeout := time.After(TEST_TIMEOUT)
+	for {
+		select {
+		case _, ok := <-client.lines:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			client.t.Fatalf("expected connection to close")
+		}
+	}
+}
+
+func (client *testClient) newGame(args string) string {
+	client.t.Helper()
+	client.send(strings.TrimSpace("NEW " + args))
+	id := strings.Fields(client.expect("STATUS GAME_ID"))[2]
+	client.expect("OK")
+	return id
+}
+
+func TestDisconnect(t *testing.T) {
+	server := startServer(t, SLOW_DISCONNECT)
+	a, c := connect(t, server), connect(t, server)
+	id := a.newGame("")
+	a.conn.Close()
+	deadline := time.Now().Add(TEST_TIMEOUT)
+	for {
+		c.send("LIST")
+		if !strings.Contains(c.expect("STATUS LIST"), id) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected abandoned game %v to be removed", id)
+		}
+		c.expect("OK")
+	}
+	c.send("JOIN " + id)
+	c.expect("ERROR NO_SUCH_GAME")
+	c.conn.Close()
+}
+
+func TestQuit(t *testing.T) {
+	a := connect(t, startServer(t, SLOW_DISCONNECT))
+	a.newGame("")
+	a.send("QUIT")
+	a.expect("OK")
+	a.expectClosed()
+}
+
+func TestResume(t *testing.T) {
+	server := startServer(t, SLOW_DISCONNECT)
+	a, b, c := connect(t, server), connect(t, server), connect(t, server)
+	defer a.conn.Close()
+	defer c.conn.Close()
+	id := a.newGame("")
+	b.send("JOIN " + id)
+	color := strings.Fields(b.expect("STATUS YOU_ARE"))[2]
+	token := strings.Fields(b.expect("STATUS SESSION"))[2]
+	b.expect("OK")
+	b.conn.Close()
+	a.expect("STATUS DISCONNECTED " + color)
+	c.send("RESUME nonsense")
+	c.expect("ERROR NO_SUCH_SESSION")
+	c.send("RESUME " + token)
+	c.expect("STATUS GAME_ID " + id)
+	c.expect("STATUS BOARD")
+	c.expect("STATUS YOU_ARE " + color)
+	c.expect("STATUS SESSION " + token)
+	c.expect("STATUS TURN")
+	c.expect("OK")
+	a.expect("STATUS RESUMED " + color)
+	c.send("RESUME " + token)
+	c.expect("ERROR NO_SUCH_SESSION")
+	c.conn.Close()
+	a.expect("STATUS DISCONNECTED " + color)
+	a.expect("STATUS LEFT " + color)
+	winner := a.expect("STATUS WINNER")
+	if strings.HasSuffix(winner, color) {
+		t.Errorf("expected %v to forfeit, got %v", color, winner)
+	}
+	a.send("MOVE 3 2 4 3")
+	a.expect("ERROR GAME_OVER")
+}
+
+func TestSpectatorLimits(t *testing.T) {
+	for limit, admitted := range map[int]int{1: 1, 0: 3} {
+		server := startServer(t, SLOW_DISCONNECT)
+		server.Config.MaxSpectators = limit
+		id, _ := seat(connect(t, server), connect(t, server))
+		for i := 0; i < 3; i++ {
+			spectator := connect(t, server)
+			spectator.send("SPECTATE " + id)
+			if i < admitted {
+				spectator.expect("STATUS GAME_ID " + id)
+			} else {
+				spectator.expect("ERROR CANNOT_SPECTATE")
+			}
+		}
+	}
+}
+
+func TestSessionOfRemovedGame(t *testing.T) {
+	server := startServer(t, SLOW_DISCONNECT)
+	server.init()
+	game := server.newGame(checkers.New())
+	if err := server.lobby.add(game); err != nil {
+		t.Fatal(err)
+	}
+	game.do(func() error {
+		game.remove()
+		return nil
+	})
+	server.lobby.addSession("late", game)
+	deadline := time.Now().Add(TEST_TIMEOUT)
+	for server.lobby.find(game.Id) != nil || server.lobby.findSession("late") != nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the removed game's session to be forgotten")
+		}
+		time.Sleep(TEST_GRACE / 10)
+	}
+}
+
+func TestAbandonNewGame(t *testing.T) {
+	server := startServer(t, SLOW_DISCONNECT)
+	server.init()
+	empty, joined := server.newGame(checkers.New()), server.newGame(checkers.New())
+	for _, game := range []*Game{empty, joined} {
+		if err := server.lobby.add(game); err != nil {
+			t.Fatal(err)
+		}
+	}
+	player := connect(t, server)
+	player.send("JOIN " + joined.Id)
+	player.expect("OK")
+	abandon(empty)
+	abandon(joined)
+	deadline := time.Now().Add(TEST_TIMEOUT)
+	for server.lobby.find(empty.Id) != nil {
+		if time.Now().After(deadline) {
+			t.Fatal("expected a game nobody joined to be removed")
+		}
+		time.Sleep(TEST_GRACE / 10)
+	}
+	if server.lobby.find(joined.Id) == nil {
+		t.Errorf("expected a game with a player to be kept")
+	}
+}
+
+// seat starts a game between a and b and returns its id and the clients by
+// colour.
+func seat(a, b *testClient) (string, map[string]*testClient) {
+	id := a.newGame("")
+	b.send("JOIN " + id)
+	players := map[string]*testClient{strings.Fields(b.expect("STATUS YOU_ARE"))[2]: b}
+	b.expect("OK")
+	for _, color := range []string{checkers.BLACK, checkers.RED} {
+		if players[color] == nil {
+			players[color] = a
+		}
+	}
+	return id, players
+}
+
+// play makes the first legal move for up to plies moves and returns the
+// resulting game.
+func play(players map[string]*testClient, plies int) *checkers.Game {
+	game := checkers.New()
+	for ply := 0; ply < plies && len(game.LegalMoves()) > 0; ply++ {
+		move := game.LegalMoves()[0]
+		player := players[game.Turn.Color]
+		player.send(fmt.Sprintf("MOVE %v %v %v %v", move.Src.X, move.Src.Y, move.Dst.X, move.Dst.Y))
+		player.expect("OK")
+		game.Move(move.Src, move.Dst)
+	}
+	return game
+}
+
+// flood sends commands to a client that is not reading until its queue
+// overflows, checking that other clients are still served.
+func flood(t *testing.T, server *Server, stuck *testClient) {
+	for i := 0; i < server.Config.ClientQueue*2; i++ {
+		stuck.send("LIST")
+	}
+	other := connect(t, server)
+	defer other.conn.Close()
+	other.send("LIST")
+	other.expect("OK")
+}
+
+func TestStuckReaderDisconnect(t *testing.T) {
+	server := startServer(t, SLOW_DISCONNECT)
+	stuck := dial(t, server)
+	defer stuck.conn.Close()
+	flood(t, server, stuck)
+	stuck.startReading()
+	stuck.expect("ERROR SLOW_CLIENT")
+	stuck.expectClosed()
+}
+
+func TestStuckReaderDrop(t *testing.T) {
+	server := startServer(t, SLOW_DROP)
+	stuck := dial(t, server)
+	defer stuck.conn.Close()
+	flood(t, server, stuck)
+	stuck.startReading()
+	timeout := time.After(TEST_TIMEOUT)
+	for {
+		select {
+		case line, ok := <-stuck.lines:
+			if !ok {
+				return
+			}
+			if strings.HasPrefix(line, "ERROR") {
+				t.Errorf("expected dropped client to get no error, got %v", line)
+			}
+		case <-timeout:
+			t.Fatalf("expected dropped client to be disconnected")
+		}
+	}
+}
+
+func TestStuckReaderCoalesce(t *testing.T) {
+	server := startServer(t, SLOW_COALESCE)
+	a, b := connect(t, server), connect(t, server)
+	defer a.conn.Close()
+	defer b.conn.Close()
+	id, players := seat(a, b)
+	stuck := dial(t, server)
+	defer stuck.conn.Close()
+	stuck.send("SPECTATE " + id)
+	// Commands run one at a time, so the second is only read once the
+	// spectator is in the game, before any of the moves below.
+	stuck.send("TURN")
+	game := play(players, server.Config.ClientQueue)
+	stuck.startReading()
+	stuck.expect("STATUS BOARD " + game.String())
+	stuck.expect("STATUS TURN " + game.Turn.Color)
+	stuck.send("LIST")
+	stuck.expect("OK")
+}
+
+func TestConcurrentGames(t *testing.T) {
+	server := startServer(t, SLOW_DISCONNECT)
+	t.Run("group", func(t *testing.T) {
+		for i := 0; i < 8; i++ {
+			t.Run(fmt.Sprint(i), func(t *testing.T) {
+				t.Parallel()
+				a, b := connect(t, server), connect(t, server)
+				defer a.conn.Close()
+				defer b.conn.Close()
+				_, players := seat(a, b)
+				game := play(players, 20)
+				a.send("BOARD")
+				a.expect("STATUS BOARD " + game.String())
+				a.expect("OK")
+			})
+		}
+	})
+}
+
+func TestShutdown(t *testing.T) {
+	server := New(DefaultConfig())
+	server.Logger = log.New(ioutil.Discard, "", 0)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(listener)
+	}()
+	a, b := connect(t, server), connect(t, server)
+	id, _ := seat(a, b)
+	b.conn.Close()
+	a.expect("STATUS DISCONNECTED")
+	game := server.lobby.find(id)
+	ctx, cancel := context.WithTimeout(context.Background(), TEST_TIMEOUT)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		t.Fatalf("expected server to shut down: %v", err)
+	}
+	a.expectClosed()
+	if err := <-served; err != ErrServerClosed {
+		t.Errorf("expected %v, got %v", ErrServerClosed, err)
+	}
+	select {
+	case <-game.removed:
+	default:
+		t.Errorf("expected held game %v to be removed", id)
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	server := startServer(t, SLOW_DISCONNECT)
+	id := connect(t, server).newGame("")
+	game := server.lobby.find(id)
+	server.active.Add(1)
+	defer server.active.Done()
+	ctx, cancel := context.WithTimeout(context.Background(), TEST_GRACE)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+	select {
+	case <-game.removed:
+	default:
+		t.Errorf("expected game %v to be removed after the timeout", id)
+	}
+	if err := server.lobby.do(func() error { return nil }); err != ErrServerClosed {
+		t.Errorf("expected the lobby to stop, got %v", err)
+	}
+}
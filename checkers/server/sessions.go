@@ -0,0 +1,156 @@
+package server
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/batkinson/checkers-go/checkers"
+	"io"
+	"time"
+)
+
+const SESSION_TOKEN_BYTES = 16
+
+// Session lets a player whose connection dropped reclaim their seat with
+// RESUME. While a seat is held its entry in Game.Players is nil. Sessions
+// belong to their game; the lobby only indexes them by token.
+type Session struct {
+	Token   string
+	Player  checkers.Player
+	Expires time.Time
+	timer   Timer
+}
+
+func (server *Server) newSessionToken() string {
+	b := make([]byte, SESSION_TOKEN_BYTES)
+	server.entropy.Lock()
+	defer server.entropy.Unlock()
+	if _, err := io.ReadFull(server.Entropy, b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func (session *Session) stopTimer() {
+	if session.timer != nil {
+		session.timer.Stop()
+		session.timer = nil
+	}
+}
+
+// startSession issues the token for a newly seated player. The caller
+// registers it with the lobby.
+func (game *Game) startSession(client *Client, player checkers.Player) string {
+	session := &Session{Token: game.server.newSessionToken(), Player: player}
+	game.Sessions[player] = session
+	client.send(fmt.Sprintf("STATUS SESSION %v", session.Token))
+	return session.Token
+}
+
+func (game *Game) endSession(player checkers.Player) {
+	if session, ok := game.Sessions[player]; ok {
+		session.stopTimer()
+		game.server.lobby.endSession(session.Token)
+		delete(game.Sessions, player)
+	}
+}
+
+// CanHold reports whether a player dropping out should keep their seat for
+// the grace period rather than leave. Nobody can return to a server shutting
+// down, so then they leave.
+func (game *Game) CanHold() bool {
+	return game.server.Config.ResumeGrace > 0 && !game.server.isClosing() && game.SeatsFilled() && !game.HasWinner()
+}
+
+// hold keeps a disconnected player's seat for the grace period, after which
+// they forfeit.
+func (game *Game) hold(client *Client) {
+	for p, c := range game.Players {
+		if c != client {
+			continue
+		}
+		game.server.Logger.Println("holding", client.Conn.RemoteAddr(), game.Id, p.Color)
+		client.setMembership(nil, false)
+		game.Players[p] = nil
+		session := game.Sessions[p]
+		session.Expires = game.server.Clock.Now().Add(game.server.Config.ResumeGrace)
+		session.timer = game.server.Clock.AfterFunc(game.server.Config.ResumeGrace, func() {
+			game.do(func() error {
+				game.expire(session)
+				return nil
+			})
+		})
+		game.Broadcast(fmt.Sprintf("STATUS DISCONNECTED %v", p.Color))
+	}
+}
+
+// expire forfeits a held seat unless it was resumed or abandoned meanwhile.
+func (game *Game) expire(session *Session) {
+	if game.Sessions[session.Player] != session || game.Players[session.Player] != nil || game.server.Clock.Now().Before(session.Expires) {
+		return
+	}
+	game.server.Logger.Println("expiring", game.Id, session.Player.Color)
+	game.endSession(session.Player)
+	delete(game.Players, session.Player)
+	game.Broadcast(fmt.Sprintf("STATUS LEFT %v", session.Player.Color))
+	if !game.HasWinner() {
+		game.Forfeited = session.Player
+		game.Broadcast(fmt.Sprintf("STATUS WINNER %v", game.Winner()))
+	}
+	if game.Connected() == 0 {
+		game.remove()
+	}
+}
+
+// heldSeat returns the player whose seat token holds, if it is being held.
+func (game *Game) heldSeat(token string) (checkers.Player, bool) {
+	for player, session := range game.Sessions {
+		if session.Token == token && game.Players[player] == nil {
+			return player, true
+		}
+	}
+	return checkers.NO_PLAYER, false
+}
+
+func resume(client *Client, args ...string) error {
+	if len(args) != 1 {
+		return errExpectedSession
+	}
+	token := args[0]
+	game := client.server.lobby.findSession(token)
+	if game == nil {
+		return errNoSuchSession
+	}
+	err := enter(client, game.Id, func(game *Game) error {
+		if _, held := game.heldSeat(token); !held {
+			return errNoSuchSession
+		}
+		return nil
+	}, func(game *Game) error {
+		player, held := game.heldSeat(token)
+		if !held {
+			return errNoSuchSession
+		}
+		if game.IsPlayer(client) {
+			return errAlreadyInGame
+		}
+		if game.IsSpectator(client) {
+			game.leave(client)
+		}
+		client.server.Logger.Println("resuming", client.Conn.RemoteAddr(), game.Id, player.Color)
+		game.Players[player] = client
+		client.setMembership(game, true)
+		client.send(fmt.Sprintf("STATUS GAME_ID %v", game.Id))
+		game.sendBallot(client)
+		client.send(fmt.Sprintf("STATUS BOARD %v", game.GameState))
+		client.send(fmt.Sprintf("STATUS YOU_ARE %v", player.Color))
+		client.send(fmt.Sprintf("STATUS SESSION %v", token))
+		game.Broadcast(fmt.Sprintf("STATUS RESUMED %v", player.Color), client)
+		game.Broadcast(fmt.Sprintf("STATUS TURN %v", game.Turn()))
+		return nil
+	})
+	if errors.Is(err, errNoSuchGame) {
+		return errNoSuchSession
+	}
+	return err
+}
@@ -0,0 +1,298 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// WEBSOCKET_PATH is where ServeHTTP accepts WebSocket connections. Each text
+// message a WebSocket client sends is one command, and each line the server
+// sends is one text message.
+const (
+	WEBSOCKET_PATH      = "/ws"
+	WEBSOCKET_GUID      = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	MAX_WEBSOCKET_FRAME = 64 * 1024
+	MAX_CONTROL_PAYLOAD = 125
+	CLOSE_TIMEOUT       = time.Second
+)
+
+// WebSocket opcodes and the close codes the server sends.
+const (
+	OP_CONTINUATION = 0x0
+	OP_TEXT         = 0x1
+	OP_BINARY       = 0x2
+	OP_CLOSE        = 0x8
+	OP_PING         = 0x9
+	OP_PONG         = 0xa
+
+	CLOSE_NORMAL         = 1000
+	CLOSE_PROTOCOL_ERROR = 1002
+	CLOSE_INVALID_DATA   = 1007
+	CLOSE_TOO_BIG        = 1009
+)
+
+var (
+	errUnmaskedFrame = errors.New("websocket frame from client is not masked")
+	errFrameTooBig   = errors.New("websocket frame too big")
+	errBadContinue   = errors.New("websocket continuation without a message")
+	errUnfinished    = errors.New("websocket message started before the last one finished")
+	errBadControl    = errors.New("websocket control frame fragmented or too big")
+	errManyLines     = errors.New("websocket message holds more than one line")
+	errInvalidText   = errors.New("websocket text message is not UTF-8")
+)
+
+// websocketAccept is the Sec-WebSocket-Accept answer to a client's key.
+func websocketAccept(key string) string {
+	hash := sha1.Sum([]byte(key + WEBSOCKET_GUID))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// hasToken reports whether a comma-separated header lists token.
+func hasToken(header http.Header, name, token string) bool {
+	for _, value := range header[http.CanonicalHeaderKey(name)] {
+		for _, field := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(field), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ServeWebSocket upgrades an HTTP request to a WebSocket and serves the
+// protocol on it as ServeConn does, so WebSocket and TCP clients share games.
+func (server *Server) ServeWebSocket(w http.ResponseWriter, r *http.Request) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	switch {
+	case r.Method != http.MethodGet:
+		http.Error(w, "expected GET", http.StatusMethodNotAllowed)
+		return
+	case !hasToken(r.Header, "Connection", "upgrade") || !hasToken(r.Header, "Upgrade", "websocket") || key == "":
+		http.Error(w, "expected a WebSocket upgrade", http.StatusBadRequest)
+		return
+	case r.Header.Get("Sec-WebSocket-Version") != "13":
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "unsupported WebSocket version", http.StatusUpgradeRequired)
+		return
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "connection cannot be upgraded", http.StatusInternalServerError)
+		return
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		server.Logger.Println(err)
+		return
+	}
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+	rw.WriteString("Sec-WebSocket-Accept: " + websocketAccept(key) + "\r\n\r\n")
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return
+	}
+	conn.SetDeadline(time.Time{})
+	server.ServeConn(&webSocketConn{Conn: conn, reader: rw.Reader})
+}
+
+// webSocketConn carries lines over a WebSocket. Reads return each message
+// received followed by a newline, and writes send each complete line as a
+// message. Deadlines apply to the underlying connection.
+type webSocketConn struct {
+	net.Conn
+	reader  *bufio.Reader
+	read    []byte
+	message []byte
+	text    bool
+	writing sync.Mutex
+	line    []byte
+	closed  bool
+}
+
+func (ws *webSocketConn) Read(p []byte) (int, error) {
+	for len(ws.read) == 0 {
+		if err := ws.readMessage(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, ws.read)
+	ws.read = ws.read[n:]
+	return n, nil
+}
+
+// readMessage reads frames until a data message is complete, answering
+// pings and closes along the way. A message must be a single command, so one
+// holding a newline breaks the protocol, and a text message must be UTF-8.
+func (ws *webSocketConn) readMessage() error {
+	for {
+		fin, opcode, payload, err := ws.readFrame()
+		if err == errFrameTooBig {
+			ws.closeWith(CLOSE_TOO_BIG)
+			return err
+		} else if err == errUnmaskedFrame {
+			ws.closeWith(CLOSE_PROTOCOL_ERROR)
+			return err
+		} else if err != nil {
+			return err
+		}
+		if opcode >= OP_CLOSE && (!fin || len(payload) > MAX_CONTROL_PAYLOAD) {
+			ws.closeWith(CLOSE_PROTOCOL_ERROR)
+			return errBadControl
+		}
+		switch opcode {
+		case OP_PING:
+			ws.writeFrame(OP_PONG, payload)
+		case OP_PONG:
+		case OP_CLOSE:
+			ws.closeWith(CLOSE_NORMAL)
+			return io.EOF
+		case OP_TEXT, OP_BINARY, OP_CONTINUATION:
+			if opcode == OP_CONTINUATION && ws.message == nil {
+				ws.closeWith(CLOSE_PROTOCOL_ERROR)
+				return errBadContinue
+			}
+			if opcode != OP_CONTINUATION && ws.message != nil {
+				ws.closeWith(CLOSE_PROTOCOL_ERROR)
+				return errUnfinished
+			}
+			if len(ws.message)+len(payload) > MAX_WEBSOCKET_FRAME {
+				ws.closeWith(CLOSE_TOO_BIG)
+				return errFrameTooBig
+			}
+			if bytes.IndexByte(payload, '\n') >= 0 {
+				ws.closeWith(CLOSE_PROTOCOL_ERROR)
+				return errManyLines
+			}
+			if ws.message == nil {
+				ws.message, ws.text = make([]byte, 0, len(payload)), opcode == OP_TEXT
+			}
+			ws.message = append(ws.message, payload...)
+			if fin && ws.text && !utf8.Valid(ws.message) {
+				ws.closeWith(CLOSE_INVALID_DATA)
+				return errInvalidText
+			}
+			if fin {
+				ws.read = append(ws.message, '\n')
+				ws.message = nil
+				return nil
+			}
+		default:
+			ws.closeWith(CLOSE_PROTOCOL_ERROR)
+			return errors.New("unknown websocket opcode")
+		}
+	}
+}
+
+func (ws *webSocketConn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	header := make([]byte, 2)
+	if _, err = io.ReadFull(ws.reader, header); err != nil {
+		return
+	}
+	fin, opcode = header[0]&0x80 != 0, header[0]&0x0f
+	if header[1]&0x80 == 0 {
+		err = errUnmaskedFrame
+		return
+	}
+	length := uint64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		extended := make([]byte, 2)
+		if _, err = io.ReadFull(ws.reader, extended); err != nil {
+			return
+		}
+		length = uint64(binary.BigEndian.Uint16(extended))
+	case 127:
+		extended := make([]byte, 8)
+		if _, err = io.ReadFull(ws.reader, extended); err != nil {
+			return
+		}
+		length = binary.BigEndian.Uint64(extended)
+	}
+	if length > MAX_WEBSOCKET_FRAME {
+		err = errFrameTooBig
+		return
+	}
+	mask := make([]byte, 4)
+	if _, err = io.ReadFull(ws.reader, mask); err != nil {
+		return
+	}
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(ws.reader, payload); err != nil {
+		return
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return
+}
+
+// writeFrame sends one unmasked, unfragmented frame.
+func (ws *webSocketConn) writeFrame(opcode byte, payload []byte) error {
+	ws.writing.Lock()
+	defer ws.writing.Unlock()
+	return ws.writeFrameLocked(opcode, payload)
+}
+
+func (ws *webSocketConn) writeFrameLocked(opcode byte, payload []byte) error {
+	if ws.closed {
+		return net.ErrClosed
+	}
+	frame := []byte{0x80 | opcode}
+	switch length := len(payload); {
+	case length < 126:
+		frame = append(frame, byte(length))
+	case length <= 0xffff:
+		frame = append(frame, 126, byte(length>>8), byte(length))
+	default:
+		frame = append(frame, 127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(length))
+	}
+	_, err := ws.Conn.Write(append(frame, payload...))
+	return err
+}
+
+func (ws *webSocketConn) Write(p []byte) (int, error) {
+	ws.writing.Lock()
+	defer ws.writing.Unlock()
+	ws.line = append(ws.line, p...)
+	for {
+		end := bytes.IndexByte(ws.line, '\n')
+		if end < 0 {
+			return len(p), nil
+		}
+		message := bytes.TrimRight(ws.line[:end], "\r")
+		ws.line = ws.line[end+1:]
+		if err := ws.writeFrameLocked(OP_TEXT, message); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// closeWith sends a close frame with code, unless a write is under way, and
+// closes the connection.
+func (ws *webSocketConn) closeWith(code uint16) error {
+	if ws.writing.TryLock() {
+		if !ws.closed {
+			ws.Conn.SetWriteDeadline(time.Now().Add(CLOSE_TIMEOUT))
+			ws.writeFrameLocked(OP_CLOSE, binary.BigEndian.AppendUint16(nil, code))
+			ws.closed = true
+		}
+		ws.writing.Unlock()
+	}
+	return ws.Conn.Close()
+}
+
+func (ws *webSocketConn) Close() error {
+	return ws.closeWith(CLOSE_NORMAL)
+}
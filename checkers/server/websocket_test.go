@@ -0,0 +1,263 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"github.com/batkinson/checkers-go/checkers"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// wsClient is the browser's end of a WebSocket: lines written become masked
+// text messages and messages read become lines, so a wire can drive it.
+type wsClient struct {
+	net.Conn
+	reader *bufio.Reader
+	read   []byte
+}
+
+func (ws *wsClient) writeFrame(opcode byte, payload []byte) error {
+	return ws.writeFragment(true, opcode, payload)
+}
+
+// writeFragment sends a masked frame, final or not.
+func (ws *wsClient) writeFragment(fin bool, opcode byte, payload []byte) error {
+	mask := []byte{1, 2, 3, 4}
+	frame := []byte{opcode}
+	if fin {
+		frame[0] |= 0x80
+	}
+	if len(payload) < 126 {
+		frame = append(frame, 0x80|byte(len(payload)))
+	} else {
+		frame = binary.BigEndian.AppendUint16(append(frame, 0x80|126), uint16(len(payload)))
+	}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	_, err := ws.Conn.Write(frame)
+	return err
+}
+
+func (ws *wsClient) readFrame() (byte, []byte, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(ws.reader, header); err != nil {
+		return 0, nil, err
+	}
+	length := int(header[1] & 0x7f)
+	if length == 126 {
+		extended := make([]byte, 2)
+		if _, err := io.ReadFull(ws.reader, extended); err != nil {
+			return 0, nil, err
+		}
+		length = int(binary.BigEndian.Uint16(extended))
+	}
+	payload := make([]byte, length)
+	_, err := io.ReadFull(ws.reader, payload)
+	return header[0] & 0x0f, payload, err
+}
+
+func (ws *wsClient) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\r\n"), "\n") {
+		if err := ws.writeFrame(OP_TEXT, []byte(strings.TrimRight(line, "\r"))); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (ws *wsClient) Read(p []byte) (int, error) {
+	for len(ws.read) == 0 {
+		opcode, payload, err := ws.readFrame()
+		if err != nil {
+			return 0, err
+		}
+		switch opcode {
+		case OP_TEXT:
+			ws.read = append(payload, '\r', '\n')
+		case OP_CLOSE:
+			return 0, io.EOF
+		}
+	}
+	n := copy(p, ws.read)
+	ws.read = ws.read[n:]
+	return n, nil
+}
+
+// dialWebSocket upgrades a connection to addr and returns a wire over it.
+func dialWebSocket(t *testing.T, addr string) *wire {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	key := "dGhlIHNhbXBsZSBub25jZQ=="
+	fmt.Fprintf(conn, "GET %v HTTP/1.1\r\nHost: %v\r\nUpgrade: websocket\r\nConnection: keep-alive, Upgrade\r\n", WEBSOCKET_PATH, addr)
+	fmt.Fprintf(conn, "Sec-WebSocket-Key: %v\r\nSec-WebSocket-Version: 13\r\n\r\n", key)
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.StatusCode != http.StatusSwitchingProtocols || response.Header.Get("Sec-WebSocket-Accept") != websocketAccept(key) {
+		t.Fatalf("expected an upgrade, got %v %v", response.Status, response.Header)
+	}
+	ws := &wsClient{Conn: conn, reader: reader}
+	return &wire{t, ws, bufio.NewReader(ws)}
+}
+
+// listenWebSocket serves one server over TCP and HTTP, returning both
+// addresses.
+func listenWebSocket(t *testing.T) (*Server, string, string) {
+	server := startServer(t, SLOW_DISCONNECT)
+	listeners := make([]net.Listener, 2)
+	for i := range listeners {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		listeners[i] = listener
+	}
+	go server.Serve(listeners[0])
+	go server.ServeHTTPListener(listeners[1])
+	return server, listeners[0].Addr().String(), listeners[1].Addr().String()
+}
+
+func TestWebSocketAccept(t *testing.T) {
+	if accept := websocketAccept("dGhlIHNhbXBsZSBub25jZQ=="); accept != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("expected the RFC 6455 example, got %v", accept)
+	}
+}
+
+func TestWebSocketSharesGames(t *testing.T) {
+	_, tcpAddr, httpAddr := listenWebSocket(t)
+	browser, terminal := dialWebSocket(t, httpAddr), dialWire(t, tcpAddr)
+	game := checkers.New()
+	id, browserColor := browser.create("", game.String())
+	terminalColor := terminal.join(id, browserColor, game.String())
+	browser.expect("STATUS JOINED "+terminalColor, "STATUS TURN black")
+	players := map[string]*wire{browserColor: browser, terminalColor: terminal}
+	players[checkers.BLACK].send("MOVE 1 2 2 3")
+	for _, player := range []*wire{browser, terminal} {
+		player.expect("STATUS MOVED 1 2 2 3", "STATUS TURN red")
+	}
+	players[checkers.BLACK].expect("OK")
+	browser.send("BOARD PRETTY")
+	for row := 0; row <= checkers.BOARD_DIM; row++ {
+		browser.expectMatch(regexp.MustCompile(`^STATUS PRETTY [1-8 ] `))
+	}
+	browser.expect("OK")
+	browser.send("QUIT")
+	browser.expect("OK")
+	browser.expectClosed()
+	terminal.expect("STATUS LEFT " + browserColor)
+}
+
+func TestWebSocketControlFrames(t *testing.T) {
+	_, _, httpAddr := listenWebSocket(t)
+	w := dialWebSocket(t, httpAddr)
+	ws := w.conn.(*wsClient)
+	ws.writeFrame(OP_PING, []byte("hello"))
+	ws.SetReadDeadline(time.Now().Add(TEST_TIMEOUT))
+	if opcode, payload, err := ws.readFrame(); err != nil || opcode != OP_PONG || string(payload) != "hello" {
+		t.Fatalf("expected pong hello, got %v %q %v", opcode, payload, err)
+	}
+	ws.Conn.Write([]byte{0x80 | OP_TEXT, 4, 'L', 'I', 'S', 'T'})
+	opcode, payload, err := ws.readFrame()
+	if err != nil || opcode != OP_CLOSE || binary.BigEndian.Uint16(payload) != CLOSE_PROTOCOL_ERROR {
+		t.Fatalf("expected an unmasked frame to close the connection, got %v %v %v", opcode, payload, err)
+	}
+}
+
+func TestWebSocketProtocolErrors(t *testing.T) {
+	type frame struct {
+		fin     bool
+		opcode  byte
+		payload string
+	}
+	_, _, httpAddr := listenWebSocket(t)
+	for name, frames := range map[string][]frame{
+		"interleaved message": {{false, OP_TEXT, "LI"}, {true, OP_TEXT, "LIST"}},
+		"fragmented ping":     {{false, OP_PING, "hello"}},
+		"oversized ping":      {{true, OP_PING, strings.Repeat("x", MAX_CONTROL_PAYLOAD+1)}},
+		"two commands":        {{true, OP_TEXT, "LIST\nLIST"}},
+		"split newline":       {{false, OP_TEXT, "LIST"}, {true, OP_CONTINUATION, "\nLIST"}},
+	} {
+		ws := dialWebSocket(t, httpAddr).conn.(*wsClient)
+		for _, f := range frames {
+			ws.writeFragment(f.fin, f.opcode, []byte(f.payload))
+		}
+		ws.SetReadDeadline(time.Now().Add(TEST_TIMEOUT))
+		opcode, payload, err := ws.readFrame()
+		if err != nil || opcode != OP_CLOSE || len(payload) != 2 || binary.BigEndian.Uint16(payload) != CLOSE_PROTOCOL_ERROR {
+			t.Errorf("expected a %v to close with a protocol error, got %v %q %v", name, opcode, payload, err)
+		}
+	}
+}
+
+func TestWebSocketInvalidText(t *testing.T) {
+	_, _, httpAddr := listenWebSocket(t)
+	ws := dialWebSocket(t, httpAddr).conn.(*wsClient)
+	ws.writeFragment(false, OP_TEXT, []byte("LIST \xe2\x82"))
+	ws.writeFragment(true, OP_CONTINUATION, []byte("\xff"))
+	ws.SetReadDeadline(time.Now().Add(TEST_TIMEOUT))
+	opcode, payload, err := ws.readFrame()
+	if err != nil || opcode != OP_CLOSE || len(payload) != 2 || binary.BigEndian.Uint16(payload) != CLOSE_INVALID_DATA {
+		t.Errorf("expected text that is not UTF-8 to close with invalid data, got %v %q %v", opcode, payload, err)
+	}
+}
+
+func TestWebSocketHandshake(t *testing.T) {
+	server := startServer(t, SLOW_DISCONNECT)
+	for _, test := range []struct {
+		header http.Header
+		status int
+	}{
+		{http.Header{}, http.StatusBadRequest},
+		{http.Header{"Upgrade": {"websocket"}, "Connection": {"Upgrade"}, "Sec-Websocket-Key": {"x"}, "Sec-Websocket-Version": {"8"}}, http.StatusUpgradeRequired},
+	} {
+		request := httptest.NewRequest("GET", WEBSOCKET_PATH, nil)
+		request.Header = test.header
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		if response.Code != test.status {
+			t.Errorf("expected %v for %v, got %v", test.status, test.header, response.Code)
+		}
+	}
+}
+
+func TestWebSocketShutdown(t *testing.T) {
+	server := startServer(t, SLOW_DISCONNECT)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	served := make(chan error)
+	go func() {
+		served <- server.ServeHTTPListener(listener)
+	}()
+	w := dialWebSocket(t, listener.Addr().String())
+	w.send("LIST")
+	w.expect("STATUS LIST ", "OK")
+	ctx, cancel := context.WithTimeout(context.Background(), TEST_TIMEOUT)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	w.expectClosed()
+	if err := <-served; err != ErrServerClosed {
+		t.Errorf("expected %v, got %v", ErrServerClosed, err)
+	}
+	if _, err := http.Get("http://" + listener.Addr().String()); err == nil {
+		t.Errorf("expected connections to be refused")
+	}
+}
@@ -0,0 +1,118 @@
+package checkers
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Snapshot is an immutable copy of a game's position, turn and any multi-jump
+// under way. Snapshots are small values that compare with ==, so they can be
+// used as map keys and passed between goroutines freely.
+//
+// Pieces are held as bit masks with one bit per playable square, bit 0 being
+// square 1.
+type Snapshot struct {
+	black  uint32
+	red    uint32
+	kings  uint32
+	turn   Player
+	jumper Pos
+}
+
+func squareBit(pos Pos) uint32 {
+	return uint32(1) << uint(Square(pos)-1)
+}
+
+// Snapshot returns the game's position as a snapshot. It fails if a piece is
+// off the playable squares or belongs to neither player, as a snapshot could
+// not hold it.
+func (game *Game) Snapshot() (Snapshot, error) {
+	snapshot := Snapshot{turn: game.Turn, jumper: NO_POS}
+	if game.Continuing() {
+		snapshot.jumper = game.Jumper
+	}
+	for pos, piece := range game.Pieces {
+		if !Usable[pos] {
+			return Snapshot{}, errors.New(fmt.Sprintf("invalid snapshot, piece on unplayable square: %v", pos))
+		}
+		bit := squareBit(pos)
+		switch piece.Player {
+		case BLACK_PLAYER:
+			snapshot.black |= bit
+		case RED_PLAYER:
+			snapshot.red |= bit
+		default:
+			return Snapshot{}, errors.New(fmt.Sprintf("invalid snapshot, unknown player at %v: %v", pos, piece.Player))
+		}
+		if piece.King {
+			snapshot.kings |= bit
+		}
+	}
+	return snapshot, nil
+}
+
+// NewSnapshot builds a snapshot from piece masks, such as those returned by
+// Masks. Jumper is NO_POS unless a multi-jump is under way.
+func NewSnapshot(black, red, kings uint32, turn Player, jumper Pos) (Snapshot, error) {
+	if black&red != 0 {
+		return Snapshot{}, errors.New("invalid snapshot, squares held by both players")
+	}
+	if kings&^(black|red) != 0 {
+		return Snapshot{}, errors.New("invalid snapshot, kings on empty squares")
+	}
+	if _, ok := Opponents[turn]; !ok {
+		return Snapshot{}, errors.New(fmt.Sprintf("invalid snapshot, unknown turn: %v", turn))
+	}
+	if !Usable[jumper] {
+		jumper = NO_POS
+	} else if piece, ok := (Snapshot{black, red, kings, turn, NO_POS}).PieceAt(jumper); !ok || piece.Player != turn {
+		return Snapshot{}, errors.New(fmt.Sprintf("invalid snapshot, no jumping piece at %v", jumper))
+	}
+	return Snapshot{black, red, kings, turn, jumper}, nil
+}
+
+func (snapshot Snapshot) Masks() (black, red, kings uint32) {
+	return snapshot.black, snapshot.red, snapshot.kings
+}
+
+func (snapshot Snapshot) Turn() Player {
+	return snapshot.turn
+}
+
+// Jumper returns the position of the piece part way through a multi-jump, or
+// NO_POS.
+func (snapshot Snapshot) Jumper() Pos {
+	return snapshot.jumper
+}
+
+func (snapshot Snapshot) PieceAt(pos Pos) (Piece, bool) {
+	if !Usable[pos] {
+		return NO_PIECE, false
+	}
+	bit := squareBit(pos)
+	piece := Piece{NO_PLAYER, snapshot.kings&bit != 0}
+	switch {
+	case snapshot.black&bit != 0:
+		piece.Player = BLACK_PLAYER
+	case snapshot.red&bit != 0:
+		piece.Player = RED_PLAYER
+	default:
+		return NO_PIECE, false
+	}
+	return piece, true
+}
+
+// Game returns a new, independent game in the snapshot's position.
+func (snapshot Snapshot) Game() *Game {
+	game := &Game{Pieces: make(map[Pos]Piece), Turn: snapshot.turn, Jumper: snapshot.jumper}
+	for pos := range Usable {
+		if piece, ok := snapshot.PieceAt(pos); ok {
+			game.Pieces[pos] = piece
+		}
+	}
+	return game
+}
+
+func (snapshot Snapshot) String() string {
+	return snapshot.Game().String()
+}
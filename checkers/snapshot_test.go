@@ -0,0 +1,119 @@
+package checkers
+
+import (
+	"sync"
+	"testing"
+)
+
+func mustSnapshot(t *testing.T, game *Game) Snapshot {
+	t.Helper()
+	snapshot, err := game.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return snapshot
+}
+
+func TestSnapshot(t *testing.T) {
+	game := New()
+	game.Pieces[Pos{1, 0}] = Piece{BLACK_PLAYER, true}
+	snapshot := mustSnapshot(t, game)
+	if snapshot.String() != game.String() || snapshot.Turn() != game.Turn {
+		t.Errorf("expected snapshot %v to match game %v", snapshot, game)
+	}
+	if piece, ok := snapshot.PieceAt(Pos{1, 0}); !ok || piece != (Piece{BLACK_PLAYER, true}) {
+		t.Errorf("expected black king at %v, got %v", Pos{1, 0}, piece)
+	}
+	if _, ok := snapshot.PieceAt(Pos{0, 3}); ok {
+		t.Errorf("expected no piece on an empty square")
+	}
+	game.Move(Pos{3, 2}, Pos{4, 3})
+	if snapshot == mustSnapshot(t, game) || snapshot.String() == game.String() {
+		t.Errorf("expected snapshot to be unaffected by later moves")
+	}
+	restored := snapshot.Game()
+	if mustSnapshot(t, restored) != snapshot {
+		t.Errorf("expected restored game to produce an equal snapshot")
+	}
+	restored.Move(Pos{3, 2}, Pos{2, 3})
+	if mustSnapshot(t, restored) == snapshot || mustSnapshot(t, restored) == mustSnapshot(t, game) {
+		t.Errorf("expected restored game to be independent of the snapshot")
+	}
+}
+
+func TestSnapshotKey(t *testing.T) {
+	seen := map[Snapshot]int{}
+	for _, ballot := range Ballots {
+		game, _ := NewBallot(ballot)
+		seen[mustSnapshot(t, game)]++
+	}
+	first, _ := FindBallot("9-13 22-18 10-14")
+	second, _ := FindBallot("10-14 22-18 9-13")
+	a, _ := NewBallot(first)
+	b, _ := NewBallot(second)
+	if mustSnapshot(t, a) != mustSnapshot(t, b) || seen[mustSnapshot(t, a)] != 2 {
+		t.Errorf("expected transposed ballots to share a snapshot key")
+	}
+	red := New()
+	red.Turn = RED_PLAYER
+	if mustSnapshot(t, red) == mustSnapshot(t, New()) {
+		t.Errorf("expected turn to distinguish snapshots")
+	}
+}
+
+func TestSnapshotUnplayable(t *testing.T) {
+	light := New()
+	light.Pieces[Pos{0, 0}] = Piece{RED_PLAYER, false}
+	if _, err := light.Snapshot(); err == nil {
+		t.Errorf("expected a piece on a light square to be rejected")
+	}
+	unowned := New()
+	unowned.Pieces[Pos{0, 3}] = Piece{NO_PLAYER, false}
+	if _, err := unowned.Snapshot(); err == nil {
+		t.Errorf("expected a piece belonging to neither player to be rejected")
+	}
+}
+
+func TestSnapshotConcurrent(t *testing.T) {
+	snapshot := mustSnapshot(t, New())
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			game := snapshot.Game()
+			for _, move := range game.LegalMoves() {
+				next := game.Clone()
+				next.Move(move.Src, move.Dst)
+			}
+		}()
+	}
+	wg.Wait()
+	if snapshot != mustSnapshot(t, New()) {
+		t.Errorf("expected shared snapshot to be unchanged")
+	}
+}
+
+func TestNewSnapshot(t *testing.T) {
+	black, red, kings := mustSnapshot(t, New()).Masks()
+	snapshot, err := NewSnapshot(black, red, kings, BLACK_PLAYER, NO_POS)
+	if err != nil || snapshot != mustSnapshot(t, New()) {
+		t.Errorf("expected masks to round-trip, got %v, %v", snapshot, err)
+	}
+	if _, err := NewSnapshot(1, 1, 0, BLACK_PLAYER, NO_POS); err == nil {
+		t.Errorf("expected overlapping masks to be rejected")
+	}
+	if _, err := NewSnapshot(1, 0, 2, BLACK_PLAYER, NO_POS); err == nil {
+		t.Errorf("expected kings on empty squares to be rejected")
+	}
+	if _, err := NewSnapshot(1, 2, 0, NO_PLAYER, NO_POS); err == nil {
+		t.Errorf("expected invalid turn to be rejected")
+	}
+	if _, err := NewSnapshot(1, 2, 0, BLACK_PLAYER, SquarePos(2)); err == nil {
+		t.Errorf("expected jumper on an opponent's piece to be rejected")
+	}
+	snapshot, err = NewSnapshot(1, 2, 0, BLACK_PLAYER, SquarePos(1))
+	if err != nil || snapshot.Jumper() != SquarePos(1) || snapshot.Game().Jumper != SquarePos(1) {
+		t.Errorf("expected jumper to be kept, got %v, %v", snapshot.Jumper(), err)
+	}
+}
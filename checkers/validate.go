@@ -0,0 +1,149 @@
+package checkers
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const MAX_PIECES = 12
+
+type Problem int
+
+const (
+	OFF_BOARD Problem = iota
+	LIGHT_SQUARE
+	UNKNOWN_PLAYER
+	UNCROWNED_MAN
+	TOO_MANY_PIECES
+	UNKNOWN_TURN
+	INVALID_JUMPER
+)
+
+var ProblemNames = map[Problem]string{
+	OFF_BOARD:       "piece off the board",
+	LIGHT_SQUARE:    "piece on a light square",
+	UNKNOWN_PLAYER:  "piece belongs to no player",
+	UNCROWNED_MAN:   "man on the king row should be a king",
+	TOO_MANY_PIECES: "too many pieces",
+	UNKNOWN_TURN:    "turn belongs to no player",
+	INVALID_JUMPER:  "jumping piece cannot continue",
+}
+
+func (problem Problem) String() string {
+	return ProblemNames[problem]
+}
+
+// Finding is one problem with a position. Pos is NO_POS for problems that
+// concern the whole board rather than one square.
+type Finding struct {
+	Problem Problem
+	Pos     Pos
+	Player  Player
+}
+
+func (finding Finding) String() string {
+	switch {
+	case finding.Problem == TOO_MANY_PIECES:
+		return fmt.Sprintf("%v for %v", finding.Problem, finding.Player.Color)
+	case finding.Pos == NO_POS:
+		return finding.Problem.String()
+	}
+	return fmt.Sprintf("%v at %v", finding.Problem, finding.Pos)
+}
+
+var ErrIllegalPosition = errors.New("illegal position")
+
+// ValidationError reports every finding for a position rejected by
+// ParseStrict. It wraps ErrIllegalPosition.
+type ValidationError struct {
+	Findings []Finding
+}
+
+func (err *ValidationError) Error() string {
+	problems := make([]string, len(err.Findings))
+	for i, finding := range err.Findings {
+		problems[i] = finding.String()
+	}
+	return fmt.Sprintf("%v: %v", ErrIllegalPosition, strings.Join(problems, ", "))
+}
+
+func (err *ValidationError) Unwrap() error {
+	return ErrIllegalPosition
+}
+
+func kingRow(player Player) int {
+	if player == BLACK_PLAYER {
+		return BOARD_DIM - 1
+	}
+	return 0
+}
+
+// Validate reports every problem that makes the game's position impossible
+// to reach in play. Findings for squares on the board come first, in board
+// order, then pieces off the board by row and column. An empty result means
+// the position is legal.
+func Validate(game *Game) []Finding {
+	findings := []Finding{}
+	counts := map[Player]int{}
+	for y := 0; y < BOARD_DIM; y++ {
+		for x := 0; x < BOARD_DIM; x++ {
+			pos := Pos{x, y}
+			piece, ok := game.Pieces[pos]
+			if !ok {
+				continue
+			}
+			if _, known := Opponents[piece.Player]; !known {
+				findings = append(findings, Finding{UNKNOWN_PLAYER, pos, piece.Player})
+				continue
+			}
+			counts[piece.Player]++
+			if !Usable[pos] {
+				findings = append(findings, Finding{LIGHT_SQUARE, pos, piece.Player})
+			} else if !piece.King && pos.Y == kingRow(piece.Player) {
+				findings = append(findings, Finding{UNCROWNED_MAN, pos, piece.Player})
+			}
+		}
+	}
+	offBoard := []Finding{}
+	for pos, piece := range game.Pieces {
+		if pos.X < 0 || pos.X >= BOARD_DIM || pos.Y < 0 || pos.Y >= BOARD_DIM {
+			offBoard = append(offBoard, Finding{OFF_BOARD, pos, piece.Player})
+		}
+	}
+	sort.Slice(offBoard, func(i, j int) bool {
+		a, b := offBoard[i].Pos, offBoard[j].Pos
+		return a.Y < b.Y || a.Y == b.Y && a.X < b.X
+	})
+	findings = append(findings, offBoard...)
+	for _, player := range []Player{BLACK_PLAYER, RED_PLAYER} {
+		if counts[player] > MAX_PIECES {
+			findings = append(findings, Finding{TOO_MANY_PIECES, NO_POS, player})
+		}
+	}
+	if _, known := Opponents[game.Turn]; !known {
+		findings = append(findings, Finding{UNKNOWN_TURN, NO_POS, game.Turn})
+	}
+	if game.Continuing() {
+		piece, ok := game.Pieces[game.Jumper]
+		if !ok || piece.Player != game.Turn || !game.jumpPossibleFrom(game.Jumper) {
+			findings = append(findings, Finding{INVALID_JUMPER, game.Jumper, game.Turn})
+		}
+	}
+	return findings
+}
+
+// ParseStrict is Parse for positions that must be legal. It returns a
+// *ValidationError listing every problem when the board parses but could not
+// occur in play.
+func ParseStrict(s string) (*Game, error) {
+	game, err := Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if findings := Validate(game); len(findings) > 0 {
+		return nil, &ValidationError{findings}
+	}
+	return game, nil
+}
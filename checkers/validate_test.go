@@ -0,0 +1,112 @@
+package checkers
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	if findings := Validate(New()); len(findings) != 0 {
+		t.Errorf("expected new game to be legal, got %v", findings)
+	}
+	game, _ := Parse("b*******|********|********|***r****|********|********|********|**b*****")
+	expected := []Finding{
+		{LIGHT_SQUARE, Pos{0, 0}, BLACK_PLAYER},
+		{LIGHT_SQUARE, Pos{3, 3}, RED_PLAYER},
+		{UNCROWNED_MAN, Pos{2, 7}, BLACK_PLAYER},
+	}
+	findings := Validate(game)
+	if len(findings) != len(expected) {
+		t.Fatalf("expected findings %v, got %v", expected, findings)
+	}
+	for i := range expected {
+		if findings[i] != expected[i] {
+			t.Errorf("expected finding %v, got %v", expected[i], findings[i])
+		}
+	}
+	game, _ = Parse("*r******|********|********|********|********|********|********|********")
+	if findings := Validate(game); len(findings) != 1 || findings[0] != (Finding{UNCROWNED_MAN, Pos{1, 0}, RED_PLAYER}) {
+		t.Errorf("expected uncrowned red man, got %v", findings)
+	}
+}
+
+func TestValidateCounts(t *testing.T) {
+	game := New()
+	game.Pieces[Pos{0, 3}] = Piece{BLACK_PLAYER, false}
+	game.Pieces[Pos{10, 3}] = Piece{RED_PLAYER, false}
+	game.Turn = NO_PLAYER
+	expected := []Finding{
+		{OFF_BOARD, Pos{10, 3}, RED_PLAYER},
+		{TOO_MANY_PIECES, NO_POS, BLACK_PLAYER},
+		{UNKNOWN_TURN, NO_POS, NO_PLAYER},
+	}
+	findings := Validate(game)
+	if len(findings) != len(expected) {
+		t.Fatalf("expected findings %v, got %v", expected, findings)
+	}
+	for i := range expected {
+		if findings[i] != expected[i] {
+			t.Errorf("expected finding %v, got %v", expected[i], findings[i])
+		}
+	}
+}
+
+func TestValidateOffBoardOrder(t *testing.T) {
+	game := &Game{Pieces: map[Pos]Piece{}, Turn: BLACK_PLAYER, Jumper: NO_POS}
+	expected := []Pos{{3, -1}, {-1, 2}, {9, 2}, {0, 8}, {5, 8}}
+	for _, pos := range expected {
+		game.Pieces[pos] = Piece{RED_PLAYER, true}
+	}
+	for i := 0; i < 20; i++ {
+		findings := Validate(game)
+		for j, pos := range expected {
+			if findings[j] != (Finding{OFF_BOARD, pos, RED_PLAYER}) {
+				t.Fatalf("expected off-board findings in order %v, got %v", expected, findings)
+			}
+		}
+	}
+}
+
+func TestValidateJumper(t *testing.T) {
+	game, _ := Parse("********|********|********|********|***b****|****r***|********|********")
+	game.Jumper = Pos{3, 4}
+	if findings := Validate(game); len(findings) != 0 {
+		t.Errorf("expected continuing jump to be legal, got %v", findings)
+	}
+	game.Turn = RED_PLAYER
+	if findings := Validate(game); len(findings) != 1 || findings[0].Problem != INVALID_JUMPER {
+		t.Errorf("expected invalid jumper, got %v", findings)
+	}
+}
+
+func TestValidatePlay(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		game := New()
+		for plies, moves := 0, game.LegalMoves(); plies < 300 && len(moves) > 0; plies, moves = plies+1, game.LegalMoves() {
+			move := moves[rng.Intn(len(moves))]
+			game.Move(move.Src, move.Dst)
+			if findings := Validate(game); len(findings) != 0 {
+				t.Fatalf("expected legal position after %v, got %v\n%v", move, findings, game)
+			}
+		}
+	}
+}
+
+func TestParseStrict(t *testing.T) {
+	if game, err := ParseStrict(New().String()); err != nil || game.String() != New().String() {
+		t.Errorf("expected new game to parse strictly, got %v", err)
+	}
+	_, err := ParseStrict("b*******|********|********|********|********|********|********|********")
+	var validation *ValidationError
+	if !errors.Is(err, ErrIllegalPosition) || !errors.As(err, &validation) || len(validation.Findings) != 1 {
+		t.Fatalf("expected one finding, got %v", err)
+	}
+	if err.Error() != "illegal position: piece on a light square at {0 0}" {
+		t.Errorf("unexpected message: %v", err)
+	}
+	if _, err := ParseStrict("bad"); err == nil || errors.Is(err, ErrIllegalPosition) {
+		t.Errorf("expected parse error, got %v", err)
+	}
+}